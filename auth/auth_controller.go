@@ -2,10 +2,16 @@ package auth
 
 import (
 	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
 	"github.com/gofiber/fiber/v2"
 	"log"
 )
 
+const (
+	authUserLocalsKey   = "authUser"
+	authClaimsLocalsKey = "authClaims"
+)
+
 type Handler struct {
 	Service Service
 }
@@ -19,19 +25,35 @@ func NewHandler(service Service) Handler {
 func (h *Handler) AuthUserHandler(c *fiber.Ctx) error {
 	log.Println("User authorization")
 	bearerToken := c.Get("Authorization")
-	if h.Service.VerifyToken(bearerToken, "user") || h.Service.VerifyToken(bearerToken, "admin") {
+	user, claims, err := h.Service.VerifyToken(bearerToken, model.Roles...)
+	if err == nil {
+		setAuthLocals(c, user, claims)
 		return c.Next()
 	}
 	log.Println("Unauthorized user")
 	return &fiber.Error{Code: 401, Message: errors.Unauthorized.Error()}
 }
 
-func (a *Handler) AuthAdminHandler(c *fiber.Ctx) error {
-	log.Println("Admin authorization")
-	bearerToken := c.Get("Authorization")
-	if a.Service.VerifyToken(bearerToken, "admin") {
-		return c.Next()
+// GetAuthUser returns the user verified by the auth middleware, or nil when the route
+// is not behind it.
+func GetAuthUser(c *fiber.Ctx) *model.User {
+	user, ok := c.Locals(authUserLocalsKey).(*model.User)
+	if !ok {
+		return nil
 	}
-	log.Println("Unauthorized admin")
-	return &fiber.Error{Code: 401, Message: errors.Unauthorized.Error()}
+	return user
+}
+
+// GetAuthClaims returns the claims of the token verified by the auth middleware.
+func GetAuthClaims(c *fiber.Ctx) *model.CustomClaims {
+	claims, ok := c.Locals(authClaimsLocalsKey).(*model.CustomClaims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
+
+func setAuthLocals(c *fiber.Ctx, user *model.User, claims *model.CustomClaims) {
+	c.Locals(authUserLocalsKey, user)
+	c.Locals(authClaimsLocalsKey, claims)
 }
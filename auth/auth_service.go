@@ -1,14 +1,11 @@
 package auth
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/anilaydinn/socium-be/errors"
 	"github.com/anilaydinn/socium-be/model"
 	"github.com/anilaydinn/socium-be/repository"
-	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt"
-
-	"strings"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
 )
 
 type Service struct {
@@ -21,44 +18,46 @@ func NewService(repository repository.Repository) Service {
 	}
 }
 
-func (s *Service) VerifyToken(bearerToken, role string) bool {
-	fmt.Println(bearerToken)
-	splitToken := strings.Split(strings.Replace(bearerToken, "\"", "", -1), "Bearer ")
-	if len(splitToken) != 2 {
-		return false
+// VerifyToken returns the user the bearer token was issued to when the token is valid,
+// not revoked and carries one of the given roles.
+func (s *Service) VerifyToken(bearerToken string, roles ...string) (*model.User, *model.CustomClaims, error) {
+	claims, err := ParseAccessToken(bearerToken)
+	if err != nil {
+		return nil, nil, errors.Unauthorized
+	}
+
+	if s.isTokenRevoked(*claims) {
+		return nil, nil, errors.Unauthorized
 	}
 
-	token := splitToken[1]
+	user, _ := s.Repository.GetUser(claims.Issuer)
 
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
-			return nil, &fiber.Error{Code: 400, Message: "Invalid Token"}
+	// Logging out of every device bumps the token version, so older tokens stop matching.
+	if user != nil && claims.Issuer == user.ID && claims.TokenVersion == user.TokenVersion && !user.IsBanned {
+		if claims.UserType == user.UserType && utils.Contains(roles, claims.UserType) {
+			return user, claims, nil
 		}
-		return []byte(""), nil
-	})
-
-	if err != nil {
-		return false
 	}
 
-	jwtClaims, isVerified := parsedToken.Claims.(jwt.MapClaims)
+	return nil, nil, errors.Unauthorized
+}
 
-	if !isVerified && !parsedToken.Valid {
-		return false
+func (s *Service) isTokenRevoked(claims model.CustomClaims) bool {
+	if isRevoked, ok := revokedTokens.get(claims.Id); ok {
+		return isRevoked
 	}
 
-	jsonString, _ := json.Marshal(jwtClaims)
-	parsedClaims := model.CustomClaims{}
-	json.Unmarshal(jsonString, &parsedClaims)
-
-	user, _ := s.Repository.GetUser(parsedClaims.Issuer)
+	isRevoked, err := s.Repository.IsTokenRevoked(claims.Id)
+	if err != nil {
+		return true
+	}
 
-	if user != nil || parsedClaims.Issuer == user.ID {
-		if parsedClaims.UserType == user.UserType && parsedClaims.UserType == role {
-			return true
-		}
+	tokenExpiresAt := time.Unix(claims.ExpiresAt, 0)
+	cacheExpiresAt := time.Now().Add(revocationCacheTTL)
+	if isRevoked || tokenExpiresAt.Before(cacheExpiresAt) {
+		cacheExpiresAt = tokenExpiresAt
 	}
+	revokedTokens.set(claims.Id, claims.Issuer, isRevoked, cacheExpiresAt)
 
-	return false
+	return isRevoked
 }
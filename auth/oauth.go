@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthProvider is an external identity provider used for social login with the
+// authorization-code flow and PKCE.
+type OAuthProvider interface {
+	Name() string
+	AuthCodeURL(state, codeChallenge string) string
+	Exchange(code, codeVerifier string) (*OAuthUserInfo, error)
+}
+
+type OAuthUserInfo struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+var oauthProviders = struct {
+	sync.RWMutex
+	providers map[string]OAuthProvider
+}{
+	providers: map[string]OAuthProvider{},
+}
+
+func RegisterOAuthProvider(provider OAuthProvider) {
+	oauthProviders.Lock()
+	defer oauthProviders.Unlock()
+
+	oauthProviders.providers[provider.Name()] = provider
+}
+
+func GetOAuthProvider(name string) (OAuthProvider, bool) {
+	oauthProviders.RLock()
+	defer oauthProviders.RUnlock()
+
+	provider, ok := oauthProviders.providers[name]
+	return provider, ok
+}
+
+// SetupOAuthProviders registers the social login providers configured in the environment.
+// Redirect URLs are built from OAUTH_REDIRECT_BASE_URL, the public URL of this API.
+func SetupOAuthProviders() {
+	redirectBaseURL := strings.TrimSuffix(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+
+	if clientID := os.Getenv("GOOGLE_CLIENT_ID"); clientID != "" {
+		provider, err := NewOIDCProvider("google", "https://accounts.google.com", clientID, os.Getenv("GOOGLE_CLIENT_SECRET"), redirectBaseURL+"/api/oauth/google/callback")
+		if err != nil {
+			log.Println("Google login is disabled: " + err.Error())
+		} else {
+			RegisterOAuthProvider(provider)
+		}
+	}
+
+	if clientID := os.Getenv("GITHUB_CLIENT_ID"); clientID != "" {
+		RegisterOAuthProvider(NewGitHubProvider(clientID, os.Getenv("GITHUB_CLIENT_SECRET"), redirectBaseURL+"/api/oauth/github/callback"))
+	}
+}
+
+// GeneratePKCEVerifier returns a random code verifier as described in RFC 7636.
+func GeneratePKCEVerifier() (string, error) {
+	verifier := make([]byte, 32)
+	if _, err := rand.Read(verifier); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(verifier), nil
+}
+
+// PKCEChallenge returns the S256 code challenge of the verifier.
+func PKCEChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+type OIDCProvider struct {
+	name                  string
+	ClientID              string
+	ClientSecret          string
+	RedirectURL           string
+	Scopes                []string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	UserInfoEndpoint      string
+	HTTPClient            *http.Client
+}
+
+// NewOIDCProvider reads the provider endpoints from the issuer's discovery document.
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string) (*OIDCProvider, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	discovery := struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	}{}
+	err := getJSON(httpClient, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", "", &discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("issuer mismatch in discovery document of %s", name)
+	}
+
+	return &OIDCProvider{
+		name:                  name,
+		ClientID:              clientID,
+		ClientSecret:          clientSecret,
+		RedirectURL:           redirectURL,
+		Scopes:                []string{"openid", "email", "profile"},
+		AuthorizationEndpoint: discovery.AuthorizationEndpoint,
+		TokenEndpoint:         discovery.TokenEndpoint,
+		UserInfoEndpoint:      discovery.UserInfoEndpoint,
+		HTTPClient:            httpClient,
+	}, nil
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, codeChallenge string) string {
+	return authCodeURL(p.AuthorizationEndpoint, p.ClientID, p.RedirectURL, p.Scopes, state, codeChallenge)
+}
+
+func (p *OIDCProvider) Exchange(code, codeVerifier string) (*OAuthUserInfo, error) {
+	accessToken, err := exchangeAuthorizationCode(p.HTTPClient, p.TokenEndpoint, p.ClientID, p.ClientSecret, p.RedirectURL, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+	}{}
+	err = getJSON(p.HTTPClient, p.UserInfoEndpoint, accessToken, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(claims.Subject) == 0 {
+		return nil, fmt.Errorf("userinfo of %s has no subject", p.name)
+	}
+
+	return &OAuthUserInfo{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+	}, nil
+}
+
+// GitHubProvider implements OAuthProvider for GitHub, which supports OAuth2 with PKCE
+// but does not publish OpenID Connect userinfo.
+type GitHubProvider struct {
+	ClientID              string
+	ClientSecret          string
+	RedirectURL           string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	APIURL                string
+	HTTPClient            *http.Client
+}
+
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *GitHubProvider {
+	return &GitHubProvider{
+		ClientID:              clientID,
+		ClientSecret:          clientSecret,
+		RedirectURL:           redirectURL,
+		AuthorizationEndpoint: "https://github.com/login/oauth/authorize",
+		TokenEndpoint:         "https://github.com/login/oauth/access_token",
+		APIURL:                "https://api.github.com",
+		HTTPClient:            &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) AuthCodeURL(state, codeChallenge string) string {
+	return authCodeURL(p.AuthorizationEndpoint, p.ClientID, p.RedirectURL, []string{"read:user", "user:email"}, state, codeChallenge)
+}
+
+func (p *GitHubProvider) Exchange(code, codeVerifier string) (*OAuthUserInfo, error) {
+	accessToken, err := exchangeAuthorizationCode(p.HTTPClient, p.TokenEndpoint, p.ClientID, p.ClientSecret, p.RedirectURL, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	user := struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}{}
+	err = getJSON(p.HTTPClient, p.APIURL+"/user", accessToken, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	err = getJSON(p.HTTPClient, p.APIURL+"/user/emails", accessToken, &emails)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := OAuthUserInfo{Subject: fmt.Sprint(user.ID)}
+	userInfo.GivenName, userInfo.FamilyName = splitName(user.Name)
+	for _, email := range emails {
+		if email.Primary {
+			userInfo.Email = email.Email
+			userInfo.EmailVerified = email.Verified
+		}
+	}
+
+	return &userInfo, nil
+}
+
+func authCodeURL(endpoint, clientID, redirectURL string, scopes []string, state, codeChallenge string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+
+	return endpoint + separator + query.Encode()
+}
+
+func exchangeAuthorizationCode(httpClient *http.Client, tokenEndpoint, clientID, clientSecret, redirectURL, code, codeVerifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", clientID)
+	form.Set("client_secret", clientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	tokenResponse := struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&tokenResponse)
+	if err != nil {
+		return "", err
+	}
+
+	if res.StatusCode != http.StatusOK || len(tokenResponse.AccessToken) == 0 {
+		return "", fmt.Errorf("token exchange failed with status %d: %s", res.StatusCode, tokenResponse.Error)
+	}
+
+	return tokenResponse.AccessToken, nil
+}
+
+func getJSON(httpClient *http.Client, endpoint, accessToken string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(accessToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status %d", endpoint, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func splitName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	index := strings.LastIndex(name, " ")
+	if index == -1 {
+		return name, ""
+	}
+
+	return name[:index], name[index+1:]
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// bcrypt ignores everything after the first 72 bytes.
+const maxPasswordLength = 72
+
+//go:embed breached_passwords.txt
+var defaultBreachedPasswords string
+
+var breachedPasswords = struct {
+	sync.Once
+	hashes map[string]bool
+}{}
+
+type PasswordPolicy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+}
+
+// GetPasswordPolicy reads the policy from PASSWORD_MIN_LENGTH and the PASSWORD_REQUIRE_*
+// variables, falling back to 8 characters with upper case, lower case and digits.
+func GetPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        getIntEnv("PASSWORD_MIN_LENGTH", 8),
+		RequireUppercase: getBoolEnv("PASSWORD_REQUIRE_UPPERCASE", true),
+		RequireLowercase: getBoolEnv("PASSWORD_REQUIRE_LOWERCASE", true),
+		RequireDigit:     getBoolEnv("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol:    getBoolEnv("PASSWORD_REQUIRE_SYMBOL", false),
+	}
+}
+
+// Validate returns nil when the password is acceptable for the user, or every rule it breaks.
+func (policy PasswordPolicy) Validate(password string, user model.User) *errors.ValidationErrors {
+	var validationErrors []errors.ValidationError
+	addError := func(code, message string) {
+		validationErrors = append(validationErrors, errors.ValidationError{
+			Field:   "password",
+			Code:    code,
+			Message: message,
+		})
+	}
+
+	if len([]rune(password)) < policy.MinLength {
+		addError("tooShort", fmt.Sprintf("Password must be at least %d characters long.", policy.MinLength))
+	}
+	if len(password) > maxPasswordLength {
+		addError("tooLong", fmt.Sprintf("Password must be at most %d bytes long.", maxPasswordLength))
+	}
+
+	var hasUppercase, hasLowercase, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUppercase = true
+		case unicode.IsLower(r):
+			hasLowercase = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !hasUppercase {
+		addError("missingUppercase", "Password must contain an upper case letter.")
+	}
+	if policy.RequireLowercase && !hasLowercase {
+		addError("missingLowercase", "Password must contain a lower case letter.")
+	}
+	if policy.RequireDigit && !hasDigit {
+		addError("missingDigit", "Password must contain a digit.")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		addError("missingSymbol", "Password must contain a symbol.")
+	}
+
+	if containsPersonalInfo(password, user) {
+		addError("personalInfo", "Password must not be your email or name.")
+	}
+
+	if IsBreachedPassword(password) {
+		addError("breached", "Password appeared in a data breach, please choose another one.")
+	}
+
+	if len(validationErrors) == 0 {
+		return nil
+	}
+
+	return &errors.ValidationErrors{Errors: validationErrors}
+}
+
+func containsPersonalInfo(password string, user model.User) bool {
+	password = strings.ToLower(password)
+	email := strings.ToLower(user.Email)
+	localPart := strings.Split(email, "@")[0]
+	name := strings.ToLower(user.Name)
+	surname := strings.ToLower(user.Surname)
+
+	for _, value := range []string{email, localPart, name, surname, name + surname, name + " " + surname} {
+		if len(strings.TrimSpace(value)) > 0 && password == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBreachedPassword checks the SHA-1 of the password against the bundled list and the
+// optional file in BREACHED_PASSWORDS_FILE, one hex encoded hash per line.
+func IsBreachedPassword(password string) bool {
+	breachedPasswords.Do(func() {
+		breachedPasswords.hashes = map[string]bool{}
+		addBreachedPasswordHashes(bufio.NewScanner(strings.NewReader(defaultBreachedPasswords)))
+
+		if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+			file, err := os.Open(path)
+			if err != nil {
+				log.Println("Could not read breached passwords file: " + err.Error())
+				return
+			}
+			defer file.Close()
+			addBreachedPasswordHashes(bufio.NewScanner(file))
+		}
+	})
+
+	hash := sha1.Sum([]byte(password))
+	return breachedPasswords.hashes[strings.ToUpper(hex.EncodeToString(hash[:]))]
+}
+
+func addBreachedPasswordHashes(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		// Lines may carry a ":count" suffix as in the Have I Been Pwned downloads.
+		hash := strings.ToUpper(strings.TrimSpace(strings.Split(scanner.Text(), ":")[0]))
+		if len(hash) > 0 {
+			breachedPasswords.hashes[hash] = true
+		}
+	}
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	"log"
+)
+
+const (
+	PermissionDashboardRead  = "dashboard:read"
+	PermissionUsersRead      = "users:read"
+	PermissionUsersBan       = "users:ban"
+	PermissionUsersRoles     = "users:roles"
+	PermissionUsersPasswords = "users:passwords"
+	PermissionUsersDelete    = "users:delete"
+	PermissionPostsRead      = "posts:read"
+	PermissionPostsDelete    = "posts:delete"
+	PermissionContactsRead   = "contacts:read"
+	PermissionContactsDelete = "contacts:delete"
+	PermissionAuditRead      = "audit:read"
+	PermissionAuditDelete    = "audit:delete"
+)
+
+var rolePermissions = map[string][]string{
+	model.RoleUser: {},
+	model.RoleSupport: {
+		PermissionDashboardRead,
+		PermissionUsersRead,
+		PermissionContactsRead,
+		PermissionContactsDelete,
+	},
+	model.RoleModerator: {
+		PermissionDashboardRead,
+		PermissionUsersRead,
+		PermissionUsersBan,
+		PermissionPostsRead,
+		PermissionPostsDelete,
+	},
+	model.RoleAdmin: {
+		PermissionDashboardRead,
+		PermissionUsersRead,
+		PermissionUsersBan,
+		PermissionUsersRoles,
+		PermissionUsersPasswords,
+		PermissionUsersDelete,
+		PermissionPostsRead,
+		PermissionPostsDelete,
+		PermissionContactsRead,
+		PermissionContactsDelete,
+		PermissionAuditRead,
+		PermissionAuditDelete,
+	},
+}
+
+func HasPermission(role, permission string) bool {
+	return utils.Contains(rolePermissions[role], permission)
+}
+
+func GetRolePermissions(role string) []string {
+	return rolePermissions[role]
+}
+
+// RequirePermission only lets the request through when the authenticated user's role
+// grants the permission. It has to run after AuthUserHandler.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := GetAuthUser(c)
+		if user == nil {
+			return &fiber.Error{Code: 401, Message: errors.Unauthorized.Error()}
+		}
+
+		if !HasPermission(user.UserType, permission) {
+			log.Println("Missing permission " + permission)
+			return &fiber.Error{Code: 403, Message: errors.Forbidden.Error()}
+		}
+
+		return c.Next()
+	}
+}
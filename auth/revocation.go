@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Tokens that were found valid are only trusted for this long, so revocations made by
+// other instances are picked up without querying Mongo on every request.
+const revocationCacheTTL = time.Minute
+
+type revocationCache struct {
+	sync.RWMutex
+	entries map[string]revocationCacheEntry
+}
+
+type revocationCacheEntry struct {
+	userID    string
+	isRevoked bool
+	expiresAt time.Time
+}
+
+var revokedTokens = revocationCache{
+	entries: map[string]revocationCacheEntry{},
+}
+
+func (c *revocationCache) get(tokenID string) (bool, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	entry, ok := c.entries[tokenID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+
+	return entry.isRevoked, true
+}
+
+func (c *revocationCache) set(tokenID, userID string, isRevoked bool, expiresAt time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	for id, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+
+	c.entries[tokenID] = revocationCacheEntry{
+		userID:    userID,
+		isRevoked: isRevoked,
+		expiresAt: expiresAt,
+	}
+}
+
+func (c *revocationCache) revokeUser(userID string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for id, entry := range c.entries {
+		if entry.userID == userID {
+			entry.isRevoked = true
+			c.entries[id] = entry
+		}
+	}
+}
+
+// CacheRevokedToken records a revoked token locally so it is rejected without a database lookup.
+func CacheRevokedToken(tokenID, userID string, expiresAt time.Time) {
+	revokedTokens.set(tokenID, userID, true, expiresAt)
+}
+
+// CacheRevokedUserTokens marks every cached token of the user as revoked.
+func CacheRevokedUserTokens(userID string) {
+	revokedTokens.revokeUser(userID)
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/golang-jwt/jwt"
+	"strings"
+	"time"
+)
+
+func GenerateAccessToken(user model.User) (string, *model.CustomClaims, error) {
+	now := time.Now().UTC()
+
+	claims := model.CustomClaims{
+		UserType:     user.UserType,
+		TokenVersion: user.TokenVersion,
+	}
+	claims.Id = utils.GenerateUUID(0)
+	claims.Issuer = user.ID
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(utils.GetAccessTokenTTL()).Unix()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(utils.GetJWTSecret())
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &claims, nil
+}
+
+func ParseAccessToken(bearerToken string) (*model.CustomClaims, error) {
+	splitToken := strings.Split(strings.Replace(bearerToken, "\"", "", -1), "Bearer ")
+	if len(splitToken) != 2 {
+		return nil, errors.Unauthorized
+	}
+
+	claims := model.CustomClaims{}
+	parsedToken, err := jwt.ParseWithClaims(splitToken[1], &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.Unauthorized
+		}
+		return utils.GetJWTSecret(), nil
+	})
+	if err != nil || !parsedToken.Valid {
+		return nil, errors.Unauthorized
+	}
+
+	if claims.Id == "" || claims.IssuedAt == 0 || claims.ExpiresAt == 0 {
+		return nil, errors.Unauthorized
+	}
+
+	return &claims, nil
+}
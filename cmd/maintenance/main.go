@@ -0,0 +1,79 @@
+// Command maintenance moves legacy post likes to reactions, inline images to the media
+// storage, single post images to media lists and embedded friends and friend requests to
+// friendships, gives usernames to users without one and locations to users with coordinates,
+// extracts the hashtags of older posts and comments, removes the posts, comments, revisions,
+// reactions, friendships, follows and references left behind by deleted posts and users,
+// purges posts whose recovery period has ended and refreshes the friend suggestions. It is
+// meant to run on a schedule from a single instance.
+package main
+
+import (
+	"log"
+
+	"github.com/anilaydinn/socium-be/repository"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/storage"
+	"github.com/anilaydinn/socium-be/utils"
+)
+
+func main() {
+	repository := repository.NewRepository(utils.GetDBUrl())
+	service := service.NewService(repository)
+
+	migratedPosts, err := repository.MigrateLegacyLikes()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Moved the likes of %d posts to reactions", migratedPosts)
+
+	storage.SetupStorage()
+	migratedImages, err := service.MigrateInlineImages()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Moved %d inline images to the media storage", migratedImages)
+
+	migratedPosts, err = repository.MigratePostMedia()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Moved the image of %d posts to media lists", migratedPosts)
+
+	migratedUsers, err := repository.MigrateFriendships()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Moved the friends and friend requests of %d users to friendships", migratedUsers)
+
+	assignedUsernames, err := service.AssignMissingUsernames()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Gave usernames to %d users", assignedUsernames)
+
+	locatedUsers, err := repository.MigrateUserLocations()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Gave locations to %d users", locatedUsers)
+
+	taggedDocuments, err := service.BackfillHashtags()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Extracted the hashtags of %d posts and comments", taggedDocuments)
+
+	report, err := service.CleanOrphans()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Deleted %d posts, %d comments, %d post revisions, %d reactions, %d friendships and %d follows, updated %d posts and %d users",
+		report.DeletedPosts, report.DeletedComments, report.DeletedPostRevisions, report.DeletedReactions, report.DeletedFriendships, report.DeletedFollows, report.UpdatedPosts, report.UpdatedUsers)
+
+	refreshedUsers, err := service.RefreshSuggestions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Refreshed the suggestions of %d users", refreshedUsers)
+}
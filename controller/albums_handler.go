@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetUserPhotosHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	q := new(model.GetPhotosQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	photos, err := h.service.GetUserPhotos(*authUser, userID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(photos)
+	case errors.InvalidCursor:
+		c.Status(fiber.StatusBadRequest)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetUserAlbumsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+
+	albums, err := h.service.GetUserAlbums(*authUser, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(albums)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) CreateAlbumHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	albumDTO := model.AlbumDTO{}
+	err := c.BodyParser(&albumDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	album, err := h.service.CreateAlbum(*authUser, albumDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusCreated)
+		c.JSON(album)
+	case errors.InvalidAlbum:
+		c.Status(fiber.StatusBadRequest)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetAlbumHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	albumID := c.Params("albumID")
+
+	album, err := h.service.GetAlbum(*authUser, albumID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(album)
+	case errors.AlbumNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) UpdateAlbumHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	albumID := c.Params("albumID")
+	albumDTO := model.AlbumDTO{}
+	err := c.BodyParser(&albumDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	album, err := h.service.UpdateAlbum(*authUser, albumID, albumDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(album)
+	case errors.InvalidAlbum:
+		c.Status(fiber.StatusBadRequest)
+	case errors.AlbumNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) DeleteAlbumHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	albumID := c.Params("albumID")
+
+	err := h.service.DeleteAlbum(*authUser, albumID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.AlbumNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AddAlbumItemHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	albumID := c.Params("albumID")
+	albumItemDTO := model.AlbumItemDTO{}
+	err := c.BodyParser(&albumItemDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	album, err := h.service.AddAlbumItem(*authUser, albumID, albumItemDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(album)
+	case errors.InvalidMedia:
+		c.Status(fiber.StatusBadRequest)
+	case errors.AlbumNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) RemoveAlbumItemHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	albumID := c.Params("albumID")
+	postID := c.Params("postID")
+	mediaID := c.Params("mediaID")
+
+	album, err := h.service.RemoveAlbumItem(*authUser, albumID, postID, mediaID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(album)
+	case errors.AlbumNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) AdminUnlockUserHandler(c *fiber.Ctx) error {
+	userID := c.Params("userID")
+	if len(userID) == 0 {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	err := h.service.AdminUnlockUser(userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AdminGetAuditEventsHandler(c *fiber.Ctx) error {
+	auditEvents, err := h.service.GetAuditEvents()
+
+	switch err {
+	case nil:
+		c.JSON(auditEvents)
+		c.Status(fiber.StatusOK)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AdminClearAuditEventsHandler(c *fiber.Ctx) error {
+	err := h.service.ClearAuditEvents()
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
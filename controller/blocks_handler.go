@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetUserProfileHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+
+	profile, err := h.service.GetUserProfile(*authUser, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewProfileUserView(*profile))
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) BlockUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	err := h.service.BlockUser(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.InvalidBlock:
+		c.Status(fiber.StatusBadRequest)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) UnblockUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	err := h.service.UnblockUser(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetBlockedUsersHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+
+	users, err := h.service.GetBlockedUsers(*authUser, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewPublicUserViews(users))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) MuteUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	user, err := h.service.MuteUser(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewSelfUserView(*user))
+	case errors.InvalidBlock:
+		c.Status(fiber.StatusBadRequest)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) UnmuteUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	user, err := h.service.UnmuteUser(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewSelfUserView(*user))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetMutedUsersHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+
+	users, err := h.service.GetMutedUsers(*authUser, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewPublicUserViews(users))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetPostCommentsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	q := new(model.GetCommentsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	comments, err := h.service.GetPostComments(*authUser, postID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(comments)
+	case errors.InvalidCursor:
+		c.Status(fiber.StatusBadRequest)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) UpdateCommentHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	commentID := c.Params("commentID")
+	commentDTO := model.CommentDTO{}
+	err := c.BodyParser(&commentDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	comment, err := h.service.UpdateComment(*authUser, postID, commentID, commentDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(comment)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) DeleteCommentHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	commentID := c.Params("commentID")
+
+	err := h.service.DeleteComment(*authUser, postID, commentID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) FollowUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	err := h.service.FollowUser(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.InvalidFollow:
+		c.Status(fiber.StatusBadRequest)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.AlreadyFollowing:
+		c.Status(fiber.StatusConflict)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) UnfollowUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	err := h.service.UnfollowUser(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.FollowNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetFollowersHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	q := new(model.GetFollowsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	response, err := h.service.GetFollowers(*authUser, userID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(response)
+	case errors.InvalidCursor:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetFollowingHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	q := new(model.GetFollowsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	response, err := h.service.GetFollowing(*authUser, userID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(response)
+	case errors.InvalidCursor:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetMutualFriendsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+
+	friends, err := h.service.GetMutualFriends(*authUser, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewPublicUserViews(friends))
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetConnectionsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	q := new(model.GetConnectionsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	connections, err := h.service.GetConnections(*authUser, userID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(connections)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetConnectionHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+	q := new(model.GetConnectionsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	connection, err := h.service.GetConnection(*authUser, userID, targetID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(connection)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound, errors.NotConnected:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetTrendingHashtagsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	q := new(model.GetTrendingHashtagsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	trendingHashtags, err := h.service.GetTrendingHashtags(*q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(trendingHashtags)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetHashtagPostsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	hashtag := c.Params("hashtag")
+	q := new(model.GetHashtagPostsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	posts, err := h.service.GetHashtagPosts(*authUser, hashtag, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(posts)
+	case errors.InvalidCursor:
+		c.Status(fiber.StatusBadRequest)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
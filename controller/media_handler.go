@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// UploadMediaHandler stores the image sent in the "file" field of a multipart form.
+func (h *Handler) UploadMediaHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+	defer file.Close()
+
+	// One byte over the limit is enough to reject the file.
+	data, err := ioutil.ReadAll(io.LimitReader(file, int64(utils.GetMaxUploadSize())+1))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	media, err := h.service.UploadMedia(*authUser, data)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusCreated)
+		c.JSON(media)
+	case errors.UnsupportedMediaType:
+		c.Status(fiber.StatusUnsupportedMediaType)
+	case errors.MediaTooLarge:
+		c.Status(fiber.StatusRequestEntityTooLarge)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetMediaFileHandler(c *fiber.Ctx) error {
+	return h.sendMediaFile(c, false)
+}
+
+func (h *Handler) GetMediaThumbnailHandler(c *fiber.Ctx) error {
+	return h.sendMediaFile(c, true)
+}
+
+// sendMediaFile serves media without authentication so it can be used in image tags. The
+// signed URLs handed out with posts and albums stand for the viewer's access, and files are
+// only cached privately for as long as the URLs last.
+func (h *Handler) sendMediaFile(c *fiber.Ctx, thumbnail bool) error {
+	mediaID := c.Params("mediaID")
+
+	data, contentType, err := h.service.GetMediaFile(mediaID, thumbnail, c.Query("expires"), c.Query("signature"))
+
+	switch err {
+	case nil:
+		c.Set(fiber.HeaderContentType, contentType)
+		c.Set(fiber.HeaderCacheControl, "private, max-age="+strconv.Itoa(int(utils.GetMediaURLTTL().Seconds())))
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		c.Status(fiber.StatusOK)
+		return c.Send(data)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.MediaNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
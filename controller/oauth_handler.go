@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+const oauthStateCookie = "oauth-state"
+
+func (h *Handler) OAuthLoginHandler(c *fiber.Ctx) error {
+	authURL, state, err := h.service.StartOAuthLogin(c.Params("provider"))
+
+	switch err {
+	case nil:
+		c.Cookie(&fiber.Cookie{
+			Name:     oauthStateCookie,
+			Value:    state,
+			Expires:  time.Now().Add(service.OAuthStateTTL),
+			HTTPOnly: true,
+			SameSite: "Lax",
+		})
+		return c.Redirect(authURL, fiber.StatusFound)
+	case errors.OAuthProviderNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) OAuthCallbackHandler(c *fiber.Ctx) error {
+	browserState := c.Cookies(oauthStateCookie)
+	c.ClearCookie(oauthStateCookie)
+
+	token, cookie, err := h.service.CompleteOAuthLogin(c.Params("provider"), c.Query("code"), c.Query("state"), browserState)
+
+	switch err {
+	case nil:
+		c.JSON(token)
+		if cookie != nil {
+			c.Cookie(cookie)
+		}
+		c.Status(fiber.StatusOK)
+	case errors.OAuthProviderNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.InvalidOAuthState:
+		c.Status(fiber.StatusBadRequest)
+	case errors.OAuthExchangeFailed:
+		c.Status(fiber.StatusUnauthorized)
+	case errors.EmailNotVerified, errors.UserNotActivated, errors.UserBanned:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
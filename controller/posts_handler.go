@@ -1,12 +1,18 @@
 package controller
 
 import (
+	"github.com/anilaydinn/socium-be/auth"
 	"github.com/anilaydinn/socium-be/errors"
 	"github.com/anilaydinn/socium-be/model"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (h *Handler) CreatePostHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	postDTO := model.PostDTO{}
 	err := c.BodyParser(&postDTO)
 	if err != nil {
@@ -14,14 +20,18 @@ func (h *Handler) CreatePostHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	post, err := h.service.CreatePost(postDTO)
+	post, err := h.service.CreatePost(*authUser, postDTO)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusCreated)
 		c.JSON(post)
+	case errors.InvalidAudience, errors.InvalidMedia:
+		c.Status(fiber.StatusBadRequest)
 	case errors.PostNotFound:
 		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -29,6 +39,11 @@ func (h *Handler) CreatePostHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) GetPostsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	q := new(model.GetPostsQuery)
 
 	if err := c.QueryParser(q); err != nil {
@@ -42,12 +57,16 @@ func (h *Handler) GetPostsHandler(c *fiber.Ctx) error {
 		isHomepage = false
 	}
 
-	posts, err := h.service.GetPosts(q.UserID, isHomepage, q.FriendIDList)
+	posts, err := h.service.GetPosts(*authUser, q.UserID, isHomepage, q.Cursor, q.Limit)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
 		c.JSON(posts)
+	case errors.InvalidCursor:
+		c.Status(fiber.StatusBadRequest)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -55,6 +74,11 @@ func (h *Handler) GetPostsHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) LikePostHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	postID := c.Params("postID")
 	likePostDTO := model.LikePostDTO{}
 	err := c.BodyParser(&likePostDTO)
@@ -63,12 +87,16 @@ func (h *Handler) LikePostHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	post, err := h.service.LikePost(postID, likePostDTO)
+	post, err := h.service.LikePost(*authUser, postID, likePostDTO)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
 		c.JSON(post)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -76,6 +104,11 @@ func (h *Handler) LikePostHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) AddPostCommentHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	postID := c.Params("postID")
 	commentDTO := model.CommentDTO{}
 	err := c.BodyParser(&commentDTO)
@@ -84,57 +117,143 @@ func (h *Handler) AddPostCommentHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	post, err := h.service.AddPostComment(postID, commentDTO)
+	post, err := h.service.AddPostComment(*authUser, postID, commentDTO)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusCreated)
 		c.JSON(post)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
 	return nil
 }
 
-func (h *Handler) DeleteAdminUserPostHandler(c *fiber.Ctx) error {
+func (h *Handler) UpdatePostHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	postID := c.Params("postID")
-	userID := c.Params("userID")
-	if len(postID) == 0 || len(userID) == 0 {
+	updatePostDTO := model.UpdatePostDTO{}
+	err := c.BodyParser(&updatePostDTO)
+	if err != nil {
 		c.Status(fiber.StatusBadRequest)
 		return nil
 	}
 
-	err := h.service.DeleteAdminUserPost(postID, userID)
+	post, err := h.service.UpdatePost(*authUser, postID, updatePostDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(post)
+	case errors.InvalidAudience, errors.InvalidMedia:
+		c.Status(fiber.StatusBadRequest)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) DeletePostHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+
+	err := h.service.DeletePost(*authUser, postID)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusNoContent)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
 	return nil
 }
 
-func (h *Handler) GetWholikesPostHandler(c *fiber.Ctx) error {
+func (h *Handler) RestorePostHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+
+	post, err := h.service.RestorePost(*authUser, postID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(post)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AdminGetPostRevisionsHandler(c *fiber.Ctx) error {
 	postID := c.Params("postID")
 	if len(postID) == 0 {
 		c.Status(fiber.StatusBadRequest)
 		return nil
 	}
-	q := new(model.WhoLikesQuery)
-
-	if err := c.QueryParser(q); err != nil {
-		return err
-	}
 
-	users, err := h.service.GetWhoLikesPost(postID, q.WhoLikesUserIDs)
+	postRevisions, err := h.service.GetPostRevisions(postID)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(users)
-	case errors.WhoLikesArrayNotEqual:
+		c.JSON(postRevisions)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) DeleteAdminUserPostHandler(c *fiber.Ctx) error {
+	postID := c.Params("postID")
+	userID := c.Params("userID")
+	if len(postID) == 0 || len(userID) == 0 {
 		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	err := h.service.DeleteAdminUserPost(postID, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
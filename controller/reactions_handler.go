@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ReactToPostHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	reactionDTO := model.ReactionDTO{}
+	err := c.BodyParser(&reactionDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	post, err := h.service.ReactToPost(*authUser, postID, reactionDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(post)
+	case errors.InvalidReactionType:
+		c.Status(fiber.StatusBadRequest)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) RemovePostReactionHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+
+	err := h.service.RemovePostReaction(*authUser, postID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetPostReactionsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	q := new(model.GetReactionsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	reactions, err := h.service.GetPostReactions(*authUser, postID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(reactions)
+	case errors.InvalidCursor, errors.InvalidReactionType:
+		c.Status(fiber.StatusBadRequest)
+	case errors.PostNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) ReactToCommentHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	commentID := c.Params("commentID")
+	reactionDTO := model.ReactionDTO{}
+	err := c.BodyParser(&reactionDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	comment, err := h.service.ReactToComment(*authUser, postID, commentID, reactionDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(comment)
+	case errors.InvalidReactionType:
+		c.Status(fiber.StatusBadRequest)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) RemoveCommentReactionHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	commentID := c.Params("commentID")
+
+	err := h.service.RemoveCommentReaction(*authUser, postID, commentID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetCommentReactionsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	postID := c.Params("postID")
+	commentID := c.Params("commentID")
+	q := new(model.GetReactionsQuery)
+
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+
+	reactions, err := h.service.GetCommentReactions(*authUser, postID, commentID, *q)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(reactions)
+	case errors.InvalidCursor, errors.InvalidReactionType:
+		c.Status(fiber.StatusBadRequest)
+	case errors.PostNotFound, errors.CommentNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
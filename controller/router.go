@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"github.com/anilaydinn/socium-be/auth"
 	"github.com/anilaydinn/socium-be/service"
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,30 +19,91 @@ func NewAPI(service *service.Service) Handler {
 func (h *Handler) SetupApp(app *fiber.App) {
 	app.Post("/api/register", h.RegisterUserHandler)
 	app.Post("/api/login", h.LoginUserHandler)
-	app.Get("/api/activation/:userID", h.ActivationHandler)
+	app.Post("/api/login/twoFactor", h.LoginTwoFactorHandler)
+	app.Post("/api/login/passwordChange", h.ChangeRequiredPasswordHandler)
+	app.Get("/api/oauth/:provider", h.OAuthLoginHandler)
+	app.Get("/api/oauth/:provider/callback", h.OAuthCallbackHandler)
+	app.Post("/api/token/refresh", h.RefreshTokenHandler)
+	app.Post("/api/logout", h.LogoutHandler)
+	app.Post("/api/logoutAll", h.LogoutAllHandler)
+	app.Get("/api/activation/:token", h.ActivationHandler)
 	app.Post("/api/forgotPassword", h.ForgotPasswordHandler)
-	app.Patch("/api/resetPassword/:userID", h.ResetPasswordHandler)
+	app.Patch("/api/resetPassword/:token", h.ResetPasswordHandler)
 	app.Get("/api/users/:userID", h.GetUserHandler)
+	app.Get("/api/media/:mediaID", h.GetMediaFileHandler)
+	app.Get("/api/media/:mediaID/thumbnail", h.GetMediaThumbnailHandler)
+	app.Post("/user/media", h.UploadMediaHandler)
 	app.Post("/user/posts", h.CreatePostHandler)
 	app.Get("/user/posts", h.GetPostsHandler)
+	app.Patch("/user/posts/:postID", h.UpdatePostHandler)
+	app.Delete("/user/posts/:postID", h.DeletePostHandler)
+	app.Patch("/user/posts/:postID/restore", h.RestorePostHandler)
 	app.Patch("/user/posts/:postID/like", h.LikePostHandler)
+	app.Post("/user/posts/:postID/reactions", h.ReactToPostHandler)
+	app.Delete("/user/posts/:postID/reactions", h.RemovePostReactionHandler)
+	app.Get("/user/posts/:postID/reactions", h.GetPostReactionsHandler)
 	app.Post("/user/posts/:postID/comments", h.AddPostCommentHandler)
+	app.Get("/user/posts/:postID/comments", h.GetPostCommentsHandler)
+	app.Patch("/user/posts/:postID/comments/:commentID", h.UpdateCommentHandler)
+	app.Delete("/user/posts/:postID/comments/:commentID", h.DeleteCommentHandler)
+	app.Post("/user/posts/:postID/comments/:commentID/reactions", h.ReactToCommentHandler)
+	app.Delete("/user/posts/:postID/comments/:commentID/reactions", h.RemoveCommentReactionHandler)
+	app.Get("/user/posts/:postID/comments/:commentID/reactions", h.GetCommentReactionsHandler)
+	app.Get("/user/hashtags", h.GetTrendingHashtagsHandler)
+	app.Get("/user/hashtags/:hashtag", h.GetHashtagPostsHandler)
+	app.Get("/user/notifications", h.GetNotificationsHandler)
+	app.Post("/user/notifications/read", h.MarkNotificationsReadHandler)
+	app.Get("/user/users/:userID", h.GetUserProfileHandler)
 	app.Patch("/user/users/:userID", h.UpdateUserHandler)
+	app.Get("/user/users/:userID/photos", h.GetUserPhotosHandler)
+	app.Get("/user/users/:userID/albums", h.GetUserAlbumsHandler)
+	app.Post("/user/albums", h.CreateAlbumHandler)
+	app.Get("/user/albums/:albumID", h.GetAlbumHandler)
+	app.Patch("/user/albums/:albumID", h.UpdateAlbumHandler)
+	app.Delete("/user/albums/:albumID", h.DeleteAlbumHandler)
+	app.Post("/user/albums/:albumID/items", h.AddAlbumItemHandler)
+	app.Delete("/user/albums/:albumID/items/:postID/:mediaID", h.RemoveAlbumItemHandler)
+	app.Post("/user/twoFactor/enroll", h.EnrollTwoFactorHandler)
+	app.Post("/user/twoFactor/verify", h.VerifyTwoFactorHandler)
+	app.Post("/user/twoFactor/disable", h.DisableTwoFactorHandler)
 	app.Post("/user/users/:targetUserID/friendRequests", h.SendFriendRequestHandler)
 	app.Get("/user/users/:userID/friendRequests", h.GetUserFriendRequestsHandler)
 	app.Post("/user/users/:userID/friendRequests/:targetID", h.AcceptOrDeclineUserFriendRequestHandler)
+	app.Get("/user/users/:userID/sentFriendRequests", h.GetUserSentFriendRequestsHandler)
+	app.Delete("/user/users/:userID/sentFriendRequests/:targetID", h.CancelFriendRequestHandler)
 	app.Get("/user/users/:userID/friends", h.GetUserFriendsHandler)
+	app.Get("/user/users/:userID/mutualFriends", h.GetMutualFriendsHandler)
+	app.Get("/user/users/:userID/connections", h.GetConnectionsHandler)
+	app.Get("/user/users/:userID/connections/:targetID", h.GetConnectionHandler)
+	app.Get("/user/users/:userID/blockedUsers", h.GetBlockedUsersHandler)
+	app.Post("/user/users/:userID/blockedUsers/:targetID", h.BlockUserHandler)
+	app.Delete("/user/users/:userID/blockedUsers/:targetID", h.UnblockUserHandler)
+	app.Get("/user/users/:userID/suggestions", h.GetUserSuggestionsHandler)
+	app.Get("/user/users/:userID/followers", h.GetFollowersHandler)
+	app.Get("/user/users/:userID/following", h.GetFollowingHandler)
+	app.Post("/user/users/:userID/following/:targetID", h.FollowUserHandler)
+	app.Delete("/user/users/:userID/following/:targetID", h.UnfollowUserHandler)
+	app.Get("/user/users/:userID/mutedUsers", h.GetMutedUsersHandler)
+	app.Post("/user/users/:userID/mutedUsers/:targetID", h.MuteUserHandler)
+	app.Delete("/user/users/:userID/mutedUsers/:targetID", h.UnmuteUserHandler)
 	app.Post("/api/contacts", h.CreateContactHandler)
 	app.Get("/user/users", h.GetUsersWithFilterHandler)
-	app.Get("/admin/users", h.GetAllUsersHandler)
-	app.Get("/admin/users/:userID", h.AdminGetUserHandler)
-	app.Get("/admin/users/:userID/posts", h.AdminGetUserPosts)
-	app.Get("/admin/dashboard", h.GetAdminDashboard)
-	app.Delete("/admin/users/:userID/posts/:postID", h.DeleteAdminUserPostHandler)
-	app.Get("/admin/contacts", h.AdminGetAllContactsHandler)
-	app.Delete("/admin/contacts/:contactID", h.AdminDeleteContactHandler)
+	app.Get("/admin/users", auth.RequirePermission(auth.PermissionUsersRead), h.GetAllUsersHandler)
+	app.Get("/admin/users/:userID", auth.RequirePermission(auth.PermissionUsersRead), h.AdminGetUserHandler)
+	app.Delete("/admin/users/:userID", auth.RequirePermission(auth.PermissionUsersDelete), h.AdminDeleteUserHandler)
+	app.Patch("/admin/users/:userID/role", auth.RequirePermission(auth.PermissionUsersRoles), h.AdminUpdateUserRoleHandler)
+	app.Patch("/admin/users/:userID/ban", auth.RequirePermission(auth.PermissionUsersBan), h.AdminBanUserHandler)
+	app.Patch("/admin/users/:userID/unlock", auth.RequirePermission(auth.PermissionUsersBan), h.AdminUnlockUserHandler)
+	app.Patch("/admin/users/:userID/forcePasswordChange", auth.RequirePermission(auth.PermissionUsersPasswords), h.AdminForcePasswordChangeHandler)
+	app.Get("/admin/auditEvents", auth.RequirePermission(auth.PermissionAuditRead), h.AdminGetAuditEventsHandler)
+	app.Delete("/admin/auditEvents", auth.RequirePermission(auth.PermissionAuditDelete), h.AdminClearAuditEventsHandler)
+	app.Get("/admin/users/:userID/posts", auth.RequirePermission(auth.PermissionPostsRead), h.AdminGetUserPosts)
+	app.Get("/admin/posts/:postID/revisions", auth.RequirePermission(auth.PermissionPostsRead), h.AdminGetPostRevisionsHandler)
+	app.Get("/admin/dashboard", auth.RequirePermission(auth.PermissionDashboardRead), h.GetAdminDashboard)
+	app.Delete("/admin/users/:userID/posts/:postID", auth.RequirePermission(auth.PermissionPostsDelete), h.DeleteAdminUserPostHandler)
+	app.Get("/admin/contacts", auth.RequirePermission(auth.PermissionContactsRead), h.AdminGetAllContactsHandler)
+	app.Delete("/admin/contacts/:contactID", auth.RequirePermission(auth.PermissionContactsDelete), h.AdminDeleteContactHandler)
 	app.Post("/user/users/:userID/near", h.GetNearUsersHandler)
 	app.Patch("/user/users/:userID/friends/:friendID", h.DeleteUserFriendHandler)
 	app.Get("/user/users/:userID/friends/:friendID", h.DeleteUserFriendHandler)
-	app.Get("/user/posts/:postID/likes", h.GetWholikesPostHandler)
 }
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) EnrollTwoFactorHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	enrollment, err := h.service.EnrollTwoFactor(*authUser)
+
+	switch err {
+	case nil:
+		c.JSON(enrollment)
+		c.Status(fiber.StatusOK)
+	case errors.TwoFactorAlreadyEnabled:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) VerifyTwoFactorHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	twoFactorCodeDTO := model.TwoFactorCodeDTO{}
+	err := c.BodyParser(&twoFactorCodeDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	recoveryCodes, err := h.service.VerifyTwoFactor(*authUser, twoFactorCodeDTO)
+
+	switch err {
+	case nil:
+		c.JSON(recoveryCodes)
+		c.Status(fiber.StatusOK)
+	case errors.TwoFactorAlreadyEnabled, errors.TwoFactorNotEnabled, errors.InvalidTwoFactorCode:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) DisableTwoFactorHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	twoFactorCodeDTO := model.TwoFactorCodeDTO{}
+	err := c.BodyParser(&twoFactorCodeDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	err = h.service.DisableTwoFactor(*authUser, twoFactorCodeDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.TwoFactorNotEnabled, errors.InvalidTwoFactorCode:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) LoginTwoFactorHandler(c *fiber.Ctx) error {
+	twoFactorLoginDTO := model.TwoFactorLoginDTO{}
+	err := c.BodyParser(&twoFactorLoginDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	token, cookie, err := h.service.LoginTwoFactor(twoFactorLoginDTO)
+
+	switch err {
+	case nil:
+		c.JSON(token)
+		c.Cookie(cookie)
+		c.Status(fiber.StatusOK)
+	case errors.Unauthorized, errors.InvalidTwoFactorCode:
+		c.Status(fiber.StatusUnauthorized)
+	case errors.UserBanned:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusBadRequest)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
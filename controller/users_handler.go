@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"github.com/anilaydinn/socium-be/auth"
 	"github.com/anilaydinn/socium-be/errors"
 	"github.com/anilaydinn/socium-be/model"
 	"github.com/anilaydinn/socium-be/utils"
@@ -20,10 +21,13 @@ func (h *Handler) RegisterUserHandler(c *fiber.Ctx) error {
 	}
 
 	user, err := h.service.RegisterUser(userDTO)
+	if isValidationError(c, err) {
+		return nil
+	}
 
 	switch err {
 	case nil:
-		c.JSON(user)
+		c.JSON(model.NewSelfUserView(*user))
 		c.Status(fiber.StatusCreated)
 	case errors.UserAlreadyRegistered:
 		c.Status(fiber.StatusBadRequest)
@@ -43,17 +47,92 @@ func (h *Handler) LoginUserHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	token, cookie, err := h.service.LoginUser(userCredentialsDTO)
+	token, cookie, err := h.service.LoginUser(userCredentialsDTO, c.IP())
 
 	switch err {
 	case nil:
 		c.JSON(token)
-		c.Cookie(cookie)
+		if cookie != nil {
+			c.Cookie(cookie)
+		}
 		c.Status(fiber.StatusOK)
 	case errors.UserNotFound:
 		c.Status(fiber.StatusBadRequest)
-	case errors.Unauthorized:
+	case errors.Unauthorized, errors.WrongPassword:
 		c.Status(fiber.StatusUnauthorized)
+	case errors.UserBanned:
+		c.Status(fiber.StatusForbidden)
+	case errors.TooManyAttempts:
+		c.Status(fiber.StatusTooManyRequests)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) RefreshTokenHandler(c *fiber.Ctx) error {
+	refreshTokenDTO := model.RefreshTokenDTO{}
+
+	err := c.BodyParser(&refreshTokenDTO)
+
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	token, err := h.service.RefreshToken(refreshTokenDTO)
+
+	switch err {
+	case nil:
+		c.JSON(token)
+		c.Status(fiber.StatusOK)
+	case errors.InvalidRefreshToken, errors.Unauthorized, errors.UserNotFound:
+		c.Status(fiber.StatusUnauthorized)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) LogoutHandler(c *fiber.Ctx) error {
+	claims := auth.GetAuthClaims(c)
+	if claims == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	refreshTokenDTO := model.RefreshTokenDTO{}
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&refreshTokenDTO)
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return nil
+		}
+	}
+
+	err := h.service.Logout(*claims, refreshTokenDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) LogoutAllHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	err := h.service.LogoutAll(authUser.ID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -61,14 +140,16 @@ func (h *Handler) LoginUserHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) ActivationHandler(c *fiber.Ctx) error {
-	userID := c.Params("userID")
+	token := c.Params("token")
 
-	user, err := h.service.Activation(userID)
+	user, err := h.service.Activation(token)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(user)
+		c.JSON(model.NewSelfUserView(*user))
+	case errors.InvalidVerificationToken, errors.UserAlreadyActivated:
+		c.Status(fiber.StatusBadRequest)
 	case errors.UserNotFound:
 		c.Status(fiber.StatusNotFound)
 	default:
@@ -85,11 +166,13 @@ func (h *Handler) ForgotPasswordHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	err = h.service.ForgotPassword(forgotPasswordDTO)
+	err = h.service.ForgotPassword(forgotPasswordDTO, c.IP())
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
+	case errors.TooManyAttempts:
+		c.Status(fiber.StatusTooManyRequests)
 	case errors.UserNotFound:
 		c.Status(fiber.StatusNotFound)
 	case errors.UserNotActivated:
@@ -101,7 +184,7 @@ func (h *Handler) ForgotPasswordHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) ResetPasswordHandler(c *fiber.Ctx) error {
-	userID := c.Params("userID")
+	token := c.Params("token")
 	resetPasswordDTO := model.ResetPasswordDTO{}
 	err := c.BodyParser(&resetPasswordDTO)
 	if err != nil {
@@ -109,11 +192,48 @@ func (h *Handler) ResetPasswordHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	err = h.service.ResetPassword(userID, resetPasswordDTO)
+	err = h.service.ResetPassword(token, resetPasswordDTO)
+	if isValidationError(c, err) {
+		return nil
+	}
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+	case errors.InvalidVerificationToken:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) ChangeRequiredPasswordHandler(c *fiber.Ctx) error {
+	passwordChangeDTO := model.PasswordChangeDTO{}
+	err := c.BodyParser(&passwordChangeDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	token, cookie, err := h.service.ChangeRequiredPassword(passwordChangeDTO)
+	if isValidationError(c, err) {
+		return nil
+	}
 
 	switch err {
 	case nil:
+		c.JSON(token)
+		if cookie != nil {
+			c.Cookie(cookie)
+		}
 		c.Status(fiber.StatusOK)
+	case errors.Unauthorized:
+		c.Status(fiber.StatusUnauthorized)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -133,7 +253,7 @@ func (h *Handler) GetUserHandler(c *fiber.Ctx) error {
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(user)
+		c.JSON(model.NewPublicUserView(*user))
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -141,6 +261,11 @@ func (h *Handler) GetUserHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) UpdateUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	userID := c.Params("userID")
 	updateUserDTO := model.UpdateUserDTO{}
 	err := c.BodyParser(&updateUserDTO)
@@ -149,13 +274,19 @@ func (h *Handler) UpdateUserHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	updatedUser, err := h.service.UpdateUser(userID, updateUserDTO)
+	updatedUser, err := h.service.UpdateUser(*authUser, userID, updateUserDTO)
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(updatedUser)
+		c.JSON(model.NewSelfUserView(*updatedUser))
+	case errors.InvalidMedia, errors.InvalidUsername:
+		c.Status(fiber.StatusBadRequest)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	case errors.UserNotFound:
 		c.Status(fiber.StatusNotFound)
+	case errors.VersionConflict, errors.UsernameTaken:
+		c.Status(fiber.StatusConflict)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -163,6 +294,11 @@ func (h *Handler) UpdateUserHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) SendFriendRequestHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	targetUserID := c.Params("targetUserID")
 	friendRequestDTO := model.FriendRequestDTO{}
 	err := c.BodyParser(&friendRequestDTO)
@@ -171,12 +307,20 @@ func (h *Handler) SendFriendRequestHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	updatedUser, err := h.service.SendFriendRequest(targetUserID, friendRequestDTO)
+	updatedUser, err := h.service.SendFriendRequest(*authUser, targetUserID, friendRequestDTO)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(updatedUser)
+		c.JSON(model.NewPublicUserView(*updatedUser))
+	case errors.InvalidFriendRequest:
+		c.Status(fiber.StatusBadRequest)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.FriendRequestExists, errors.AlreadyFriends:
+		c.Status(fiber.StatusConflict)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 
@@ -185,13 +329,20 @@ func (h *Handler) SendFriendRequestHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) GetUserFriendRequestsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	userID := c.Params("userID")
-	users, err := h.service.GetUserFriendRequests(userID)
+	users, err := h.service.GetUserFriendRequests(*authUser, userID)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(users)
+		c.JSON(model.NewPublicUserViews(users))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -199,6 +350,11 @@ func (h *Handler) GetUserFriendRequestsHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) AcceptOrDeclineUserFriendRequestHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	userID := c.Params("userID")
 	targetID := c.Params("targetID")
 	acceptOrDeclineFriendRequestDTO := model.AcceptOrDeclineFriendRequestDTO{}
@@ -208,12 +364,59 @@ func (h *Handler) AcceptOrDeclineUserFriendRequestHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	user, err := h.service.AcceptOrDeclineUserFriendRequest(userID, targetID, acceptOrDeclineFriendRequestDTO)
+	user, err := h.service.AcceptOrDeclineUserFriendRequest(*authUser, userID, targetID, acceptOrDeclineFriendRequestDTO)
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(user)
-	case errors.UserNotFound:
+		c.JSON(model.NewSelfUserView(*user))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound, errors.FriendRequestNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) GetUserSentFriendRequestsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	users, err := h.service.GetUserSentFriendRequests(*authUser, userID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewPublicUserViews(users))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) CancelFriendRequestHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+	userID := c.Params("userID")
+	targetID := c.Params("targetID")
+
+	err := h.service.CancelFriendRequest(*authUser, userID, targetID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.FriendRequestNotFound:
 		c.Status(fiber.StatusNotFound)
 	default:
 		c.Status(fiber.StatusInternalServerError)
@@ -222,14 +425,22 @@ func (h *Handler) AcceptOrDeclineUserFriendRequestHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) GetUserFriendsHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
 	userID := c.Params("userID")
 
-	friends, err := h.service.GetUserFriends(userID)
+	friends, err := h.service.GetUserFriends(*authUser, userID)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(friends)
+		c.JSON(model.NewPublicUserViews(friends))
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -237,6 +448,12 @@ func (h *Handler) GetUserFriendsHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) GetUsersWithFilterHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
 	filter := c.Query("filter")
 	var filterArr []string
 	if strings.Contains(filter, " ") {
@@ -245,12 +462,12 @@ func (h *Handler) GetUsersWithFilterHandler(c *fiber.Ctx) error {
 		filterArr = append(filterArr, filter)
 	}
 
-	users, err := h.service.GetUsersWithFilter(filterArr)
+	users, err := h.service.GetUsersWithFilter(*authUser, filterArr)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(users)
+		c.JSON(model.NewPublicUserViews(users))
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -292,7 +509,7 @@ func (h *Handler) GetAllUsersHandler(c *fiber.Ctx) error {
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(users)
+		c.JSON(model.AdminUsersPageableResponse{Users: model.NewAdminUserViews(users.Users), Page: users.Page})
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -312,7 +529,7 @@ func (h *Handler) AdminGetUserHandler(c *fiber.Ctx) error {
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(user)
+		c.JSON(model.NewAdminUserView(*user))
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
@@ -339,6 +556,11 @@ func (h *Handler) AdminGetUserPosts(c *fiber.Ctx) error {
 }
 
 func (h *Handler) GetNearUsersHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	userID := c.Params("userID")
 	if len(userID) == 0 {
 		c.Status(fiber.StatusBadRequest)
@@ -349,12 +571,14 @@ func (h *Handler) GetNearUsersHandler(c *fiber.Ctx) error {
 	if err != nil {
 		c.Status(fiber.StatusBadRequest)
 	}
-	users, err := h.service.GetNearUsers(userID, getNearUsersDTO)
+	users, err := h.service.GetNearUsers(*authUser, userID, getNearUsersDTO)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(users)
+		c.JSON(model.NewPublicUserViews(users))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 
@@ -363,6 +587,11 @@ func (h *Handler) GetNearUsersHandler(c *fiber.Ctx) error {
 }
 
 func (h *Handler) DeleteUserFriendHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
 	userID := c.Params("userID")
 	friendID := c.Params("friendID")
 	if len(userID) == 0 || len(friendID) == 0 {
@@ -370,14 +599,144 @@ func (h *Handler) DeleteUserFriendHandler(c *fiber.Ctx) error {
 		return nil
 	}
 
-	user, err := h.service.DeleteUserFriend(userID, friendID)
+	user, err := h.service.DeleteUserFriend(*authUser, userID, friendID)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewSelfUserView(*user))
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AdminUpdateUserRoleHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	userID := c.Params("userID")
+	updateUserRoleDTO := model.UpdateUserRoleDTO{}
+	err := c.BodyParser(&updateUserRoleDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	user, err := h.service.AdminUpdateUserRole(*authUser, userID, updateUserRoleDTO)
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusOK)
+		c.JSON(model.NewAdminUserView(*user))
+	case errors.InvalidRole:
+		c.Status(fiber.StatusBadRequest)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AdminBanUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	userID := c.Params("userID")
+	banUserDTO := model.BanUserDTO{}
+	err := c.BodyParser(&banUserDTO)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return nil
+	}
+
+	user, err := h.service.AdminBanUser(*authUser, userID, banUserDTO)
 
 	switch err {
 	case nil:
 		c.Status(fiber.StatusOK)
-		c.JSON(user)
+		c.JSON(model.NewAdminUserView(*user))
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
 	default:
 		c.Status(fiber.StatusInternalServerError)
 	}
 	return nil
 }
+
+func (h *Handler) AdminForcePasswordChangeHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	err := h.service.AdminForcePasswordChange(*authUser, c.Params("userID"))
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	case errors.VersionConflict:
+		c.Status(fiber.StatusConflict)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (h *Handler) AdminDeleteUserHandler(c *fiber.Ctx) error {
+	authUser := auth.GetAuthUser(c)
+	if authUser == nil {
+		c.Status(fiber.StatusUnauthorized)
+		return nil
+	}
+
+	err := h.service.AdminDeleteUser(*authUser, c.Params("userID"))
+
+	switch err {
+	case nil:
+		c.Status(fiber.StatusNoContent)
+	case errors.Forbidden:
+		c.Status(fiber.StatusForbidden)
+	case errors.UserNotFound:
+		c.Status(fiber.StatusNotFound)
+	default:
+		c.Status(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+// isValidationError writes field level errors as a 400 response and reports whether err was one.
+func isValidationError(c *fiber.Ctx, err error) bool {
+	validationErrors, ok := err.(*errors.ValidationErrors)
+	if !ok {
+		return false
+	}
+
+	c.Status(fiber.StatusBadRequest)
+	c.JSON(validationErrors)
+	return true
+}
@@ -11,5 +11,54 @@ var UserAlreadyActivated error = errors.New("User already activated!")
 var UserAlreadyRegistered error = errors.New("User already registered!")
 var UserNotActivated error = errors.New("User not activated!")
 var PostNotFound error = errors.New("Post not found!")
+var CommentNotFound error = errors.New("Comment not found!")
 var ContactNotFound error = errors.New("Contact not found!")
-var WhoLikesArrayNotEqual error = errors.New("Likes array not equal!")
+var InvalidRefreshToken error = errors.New("Invalid refresh token!")
+var Forbidden error = errors.New("Forbidden!")
+var InvalidVerificationToken error = errors.New("Invalid or expired token!")
+var InvalidRole error = errors.New("Invalid role!")
+var UserBanned error = errors.New("User banned!")
+var TwoFactorAlreadyEnabled error = errors.New("Two-factor authentication already enabled!")
+var TwoFactorNotEnabled error = errors.New("Two-factor authentication not enabled!")
+var InvalidTwoFactorCode error = errors.New("Invalid two-factor code!")
+var TooManyAttempts error = errors.New("Too many attempts, try again later!")
+var OAuthProviderNotFound error = errors.New("OAuth provider not found!")
+var InvalidOAuthState error = errors.New("Invalid OAuth state!")
+var OAuthExchangeFailed error = errors.New("OAuth login failed!")
+var EmailNotVerified error = errors.New("Email not verified!")
+var InvalidCursor error = errors.New("Invalid cursor!")
+var InvalidAudience error = errors.New("Invalid audience!")
+var InvalidReactionType error = errors.New("Invalid reaction type!")
+var VersionConflict error = errors.New("Resource was modified by another request!")
+var MediaNotFound error = errors.New("Media not found!")
+var InvalidMedia error = errors.New("Invalid media reference!")
+var UnsupportedMediaType error = errors.New("Unsupported media type!")
+var MediaTooLarge error = errors.New("Media too large!")
+var AlbumNotFound error = errors.New("Album not found!")
+var InvalidAlbum error = errors.New("Invalid album!")
+var InvalidUsername error = errors.New("Invalid username!")
+var UsernameTaken error = errors.New("Username is already taken!")
+var InvalidFriendRequest error = errors.New("Invalid friend request!")
+var FriendRequestExists error = errors.New("Friend request already exists!")
+var FriendRequestNotFound error = errors.New("Friend request not found!")
+var AlreadyFriends error = errors.New("Users are already friends!")
+var InvalidBlock error = errors.New("Users cannot block or mute themselves!")
+var InvalidFollow error = errors.New("Users cannot follow themselves!")
+var AlreadyFollowing error = errors.New("User is already followed!")
+var FollowNotFound error = errors.New("Follow not found!")
+var NotConnected error = errors.New("Users are not connected within the given depth!")
+
+type ValidationError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned when a request is rejected for one or more field level reasons.
+type ValidationErrors struct {
+	Errors []ValidationError `json:"errors"`
+}
+
+func (validationErrors *ValidationErrors) Error() string {
+	return "Validation failed!"
+}
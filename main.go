@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"github.com/anilaydinn/socium-be/auth"
 	"github.com/anilaydinn/socium-be/controller"
 	"github.com/anilaydinn/socium-be/middleware"
 	"github.com/anilaydinn/socium-be/repository"
 	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/storage"
 	"github.com/anilaydinn/socium-be/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -15,11 +17,14 @@ import (
 func main() {
 	dbURL := utils.GetDBUrl()
 
-	app := fiber.New()
+	// Leaves room for the multipart encoding around the largest accepted upload.
+	app := fiber.New(fiber.Config{BodyLimit: utils.GetMaxUploadSize() + 1<<20})
 	app.Use(cors.New())
 	app.Use(logger.New())
 	repository := repository.NewRepository(dbURL)
 	middleware.SetupMiddleWare(app, *repository)
+	auth.SetupOAuthProviders()
+	storage.SetupStorage()
 	service := service.NewService(repository)
 	api := controller.NewAPI(&service)
 
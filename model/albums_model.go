@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+type AlbumDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     int    `json:"version"`
+}
+
+type AlbumItemDTO struct {
+	PostID  string `json:"postId"`
+	MediaID string `json:"mediaId"`
+}
+
+// Album groups media items of the posts of its owner. Items are only shown to viewers
+// who can see the post they belong to.
+type Album struct {
+	ID          string      `json:"id"`
+	UserID      string      `json:"userId"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Items       []AlbumItem `json:"-"`
+	PhotoCount  int         `json:"photoCount"`
+	CoverPhoto  *Photo      `json:"coverPhoto"`
+	Photos      []Photo     `json:"photos,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Version     int         `json:"version"`
+}
+
+type AlbumItem struct {
+	PostID  string
+	MediaID string
+	AddedAt time.Time
+}
+
+// Photo is a media item of a post listed on its own.
+type Photo struct {
+	PostID    string    `json:"postId"`
+	MediaID   string    `json:"mediaId"`
+	Caption   string    `json:"caption"`
+	AltText   string    `json:"altText"`
+	Media     *Media    `json:"media"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PhotoCursor is the photo after which the next page of photos starts.
+type PhotoCursor struct {
+	CreatedAt time.Time
+	PostID    string
+	Position  int
+}
+
+type GetPhotosQuery struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+type PhotosCursorResponse struct {
+	Photos     []Photo `json:"photos"`
+	NextCursor string  `json:"nextCursor"`
+}
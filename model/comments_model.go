@@ -3,16 +3,35 @@ package model
 import "time"
 
 type Comment struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"userId"`
-	PostID    string    `json:"postId"`
-	User      *User     `json:"user"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               string          `json:"id"`
+	UserID           string          `json:"userId"`
+	PostID           string          `json:"postId"`
+	ParentCommentID  string          `json:"parentCommentId"`
+	User             *PublicUserView `json:"user"`
+	Content          string          `json:"content"`
+	Hashtags         []string        `json:"hashtags"`
+	MentionedUserIDs []string        `json:"mentionedUserIds"`
+	ReplyCount       int             `json:"replyCount"`
+	ReactionCounts   map[string]int  `json:"reactionCounts"`
+	ViewerReaction   string          `json:"viewerReaction"`
+	IsEdited         bool            `json:"isEdited"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
 }
 
 type CommentDTO struct {
-	UserID  string `json:"userId"`
-	Content string `json:"content"`
+	UserID          string `json:"userId"`
+	ParentCommentID string `json:"parentCommentId"`
+	Content         string `json:"content"`
+}
+
+type GetCommentsQuery struct {
+	ParentCommentID string `query:"parentCommentId"`
+	Cursor          string `query:"cursor"`
+	Limit           int    `query:"limit"`
+}
+
+type CommentsCursorResponse struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"nextCursor"`
 }
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Follow is a one-way relation in which FollowerID gets the posts of FolloweeID it can see in
+// its home feed. User is the other user of a follower or following list.
+type Follow struct {
+	ID         string          `json:"id"`
+	FollowerID string          `json:"followerId"`
+	FolloweeID string          `json:"followeeId"`
+	User       *PublicUserView `json:"user"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type GetFollowsQuery struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+type FollowsCursorResponse struct {
+	Follows    []Follow `json:"follows"`
+	NextCursor string   `json:"nextCursor"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// Connection is a user reachable through friendships. Degree is 1 for friends, 2 for friends
+// of friends and so on.
+type Connection struct {
+	UserID string          `json:"userId"`
+	User   *PublicUserView `json:"user"`
+	Degree int             `json:"degree"`
+}
+
+type GetConnectionsQuery struct {
+	Depth int `query:"depth"`
+	Limit int `query:"limit"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	FriendshipPending   = "pending"
+	FriendshipAccepted  = "accepted"
+	FriendshipDeclined  = "declined"
+	FriendshipCancelled = "cancelled"
+	FriendshipBlocked   = "blocked"
+)
+
+// Friendship is the relation between two users. RequesterID is the user who sent the last
+// friend request to AddresseeID. RespondedAt is set once the request is accepted, declined
+// or cancelled.
+type Friendship struct {
+	ID          string     `json:"id"`
+	RequesterID string     `json:"requesterId"`
+	AddresseeID string     `json:"addresseeId"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	RespondedAt *time.Time `json:"respondedAt"`
+}
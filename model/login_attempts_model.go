@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	AuditLock   = "lock"
+	AuditUnlock = "unlock"
+)
+
+type LoginAttempt struct {
+	Key          string    `json:"key"`
+	FailedCount  int       `json:"failedCount"`
+	LockedUntil  time.Time `json:"lockedUntil"`
+	LastFailedAt time.Time `json:"lastFailedAt"`
+}
+
+type AuditEvent struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Key         string    `json:"key"`
+	UserID      string    `json:"userId"`
+	IPAddress   string    `json:"ipAddress"`
+	LockedUntil time.Time `json:"lockedUntil"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type OrphanCleanupReport struct {
+	DeletedPosts         int `json:"deletedPosts"`
+	DeletedComments      int `json:"deletedComments"`
+	DeletedPostRevisions int `json:"deletedPostRevisions"`
+	DeletedReactions     int `json:"deletedReactions"`
+	DeletedFriendships   int `json:"deletedFriendships"`
+	DeletedFollows       int `json:"deletedFollows"`
+	UpdatedPosts         int `json:"updatedPosts"`
+	UpdatedUsers         int `json:"updatedUsers"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Media is an uploaded image. Posts and users keep its ID in Image and ProfileImage, the
+// files are in the storage under Key and ThumbnailKey and served from URL and ThumbnailURL.
+type Media struct {
+	ID                   string    `json:"id"`
+	UserID               string    `json:"userId"`
+	ContentType          string    `json:"contentType"`
+	Size                 int       `json:"size"`
+	Width                int       `json:"width"`
+	Height               int       `json:"height"`
+	Key                  string    `json:"-"`
+	ThumbnailKey         string    `json:"-"`
+	ThumbnailContentType string    `json:"-"`
+	URL                  string    `json:"url"`
+	ThumbnailURL         string    `json:"thumbnailUrl"`
+	CreatedAt            time.Time `json:"createdAt"`
+}
+
+// InlineImage is an image stored inside a post or user document before media uploads existed.
+type InlineImage struct {
+	ID     string
+	UserID string
+	Data   string
+}
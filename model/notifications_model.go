@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const NotificationMention = "mention"
+
+// Notification tells a user that another user, the actor, did something that concerns them.
+// CommentID is set when it happened in a comment of the post.
+type Notification struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"userId"`
+	Type      string          `json:"type"`
+	ActorID   string          `json:"actorId"`
+	Actor     *PublicUserView `json:"actor"`
+	PostID    string          `json:"postId"`
+	CommentID string          `json:"commentId"`
+	IsRead    bool            `json:"isRead"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type GetNotificationsQuery struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+type NotificationsCursorResponse struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    string         `json:"nextCursor"`
+}
@@ -2,25 +2,100 @@ package model
 
 import "time"
 
+const (
+	AudiencePublic  = "public"
+	AudienceFriends = "friends"
+	AudienceOnlyMe  = "onlyMe"
+	AudienceCustom  = "custom"
+)
+
+var Audiences = []string{AudiencePublic, AudienceFriends, AudienceOnlyMe, AudienceCustom}
+
+// MaxPostMedia is the number of media items a post can hold.
+const MaxPostMedia = 10
+
+// PostDTO.Image is a single media ID sent by clients that predate Media, it is ignored
+// when Media is set.
 type PostDTO struct {
-	UserID      string `json:"userId"`
-	Description string `json:"description"`
-	Image       string `json:"image"`
-	IsPrivate   bool   `json:"isPrivate"`
+	UserID          string         `json:"userId"`
+	Description     string         `json:"description"`
+	Image           string         `json:"image"`
+	Media           []PostMediaDTO `json:"media"`
+	IsPrivate       bool           `json:"isPrivate"`
+	Audience        string         `json:"audience"`
+	AudienceUserIDs []string       `json:"audienceUserIds"`
+}
+
+type PostMediaDTO struct {
+	MediaID string `json:"mediaId"`
+	Caption string `json:"caption"`
+	AltText string `json:"altText"`
+}
+
+// PostMedia is one of the ordered media items of a post. Media is filled in when posts are
+// returned.
+type PostMedia struct {
+	MediaID string `json:"mediaId"`
+	Caption string `json:"caption"`
+	AltText string `json:"altText"`
+	Media   *Media `json:"media"`
 }
 
 type Post struct {
-	ID              string    `json:"id"`
-	UserID          string    `json:"userId"`
-	User            *User     `json:"user"`
-	Description     string    `json:"description"`
-	Image           string    `json:"image"`
-	IsPrivate       bool      `json:"isPrivate"`
-	WhoLikesUserIDs []string  `json:"whoLikesUserIds"`
-	CommentIDs      []string  `json:"commentIds"`
-	Comments        []Comment `json:"comments"`
-	CreatedAt       time.Time `json:"createdAt"`
-	UpdatedAt       time.Time `json:"updatedAt"`
+	ID               string          `json:"id"`
+	UserID           string          `json:"userId"`
+	User             *PublicUserView `json:"user"`
+	Description      string          `json:"description"`
+	Image            string          `json:"image"`
+	Media            []PostMedia     `json:"media"`
+	Hashtags         []string        `json:"hashtags"`
+	MentionedUserIDs []string        `json:"mentionedUserIds"`
+	IsPrivate        bool            `json:"isPrivate"`
+	Audience         string          `json:"audience"`
+	AudienceUserIDs  []string        `json:"audienceUserIds"`
+	CommentIDs       []string        `json:"commentIds"`
+	Comments         []Comment       `json:"comments"`
+	CommentCount     int             `json:"commentCount"`
+	ReactionCounts   map[string]int  `json:"reactionCounts"`
+	ViewerReaction   string          `json:"viewerReaction"`
+	IsEdited         bool            `json:"isEdited"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
+	DeletedAt        *time.Time      `json:"deletedAt,omitempty"`
+	Version          int             `json:"version"`
+}
+
+// UpdatePostDTO.Version is the version of the post the client last read. When it is set the
+// update is rejected if the post changed since. The media of the post are kept when neither
+// Image nor Media is sent.
+type UpdatePostDTO struct {
+	Description     string          `json:"description"`
+	Image           *string         `json:"image"`
+	Media           *[]PostMediaDTO `json:"media"`
+	Audience        string          `json:"audience"`
+	AudienceUserIDs []string        `json:"audienceUserIds"`
+	Version         int             `json:"version"`
+}
+
+// PostRevision keeps what a post said before one of its edits.
+type PostRevision struct {
+	ID              string      `json:"id"`
+	PostID          string      `json:"postId"`
+	Description     string      `json:"description"`
+	Image           string      `json:"image"`
+	Media           []PostMedia `json:"media"`
+	Audience        string      `json:"audience"`
+	AudienceUserIDs []string    `json:"audienceUserIds"`
+	CreatedAt       time.Time   `json:"createdAt"`
+}
+
+// GetLegacyAudience returns the audience of posts stored before audiences existed, when
+// only the isPrivate flag was kept.
+func GetLegacyAudience(isPrivate bool) string {
+	if isPrivate {
+		return AudienceFriends
+	}
+	return AudiencePublic
 }
 
 type LikePostDTO struct {
@@ -28,11 +103,36 @@ type LikePostDTO struct {
 }
 
 type GetPostsQuery struct {
-	UserID       string   `query:"userId"`
-	Homepage     string   `query:"homepage"`
-	FriendIDList []string `query:"friendIdList"`
+	UserID   string `query:"userId"`
+	Homepage string `query:"homepage"`
+	Cursor   string `query:"cursor"`
+	Limit    int    `query:"limit"`
+}
+
+type PostsCursorResponse struct {
+	Posts      []Post `json:"posts"`
+	NextCursor string `json:"nextCursor"`
+}
+
+// Cursor is the position after which the next page starts, in createdAt then id order.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type GetHashtagPostsQuery struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+// GetTrendingHashtagsQuery.Hours is the length of the window, ending now, in which hashtag
+// uses are counted.
+type GetTrendingHashtagsQuery struct {
+	Hours int `query:"hours"`
+	Limit int `query:"limit"`
 }
 
-type WhoLikesQuery struct {
-	WhoLikesUserIDs []string `query:"whoLikesUserIds"`
+type TrendingHashtag struct {
+	Hashtag string `json:"hashtag"`
+	Count   int    `json:"count"`
 }
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionLaugh = "laugh"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+var ReactionTypes = []string{ReactionLike, ReactionLove, ReactionLaugh, ReactionSad, ReactionAngry}
+
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// Reaction is the single reaction of a user to a post or a comment. PostID is the post
+// the target belongs to, so reactions can be removed together with the post.
+type Reaction struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	TargetType string    `json:"targetType"`
+	TargetID   string    `json:"targetId"`
+	PostID     string    `json:"postId"`
+	Type       string    `json:"type"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type ReactionDTO struct {
+	Type string `json:"type"`
+}
+
+type GetReactionsQuery struct {
+	Type   string `query:"type"`
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+type ReactionView struct {
+	User      *PublicUserView `json:"user"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type ReactionsCursorResponse struct {
+	Reactions  []ReactionView `json:"reactions"`
+	NextCursor string         `json:"nextCursor"`
+}
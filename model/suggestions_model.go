@@ -0,0 +1,18 @@
+package model
+
+// Suggestion is a user the user may know. Score ranks the suggestions of a user, it adds up
+// their mutual friends, the hashtags both recently posted, the reactions and comments they
+// recently left on each other's posts and how close they live.
+type Suggestion struct {
+	UserID             string          `json:"userId"`
+	User               *PublicUserView `json:"user"`
+	Score              float64         `json:"score"`
+	MutualFriendCount  int             `json:"mutualFriendCount"`
+	SharedHashtagCount int             `json:"sharedHashtagCount"`
+	InteractionCount   int             `json:"interactionCount"`
+	IsNearby           bool            `json:"isNearby"`
+}
+
+type GetSuggestionsQuery struct {
+	Limit int `query:"limit"`
+}
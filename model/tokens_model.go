@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type RefreshToken struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	TokenHash string    `json:"-"`
+	IsRevoked bool      `json:"isRevoked"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RevokedToken struct {
+	TokenID   string    `json:"tokenId"`
+	UserID    string    `json:"userId"`
+	RevokedAt time.Time `json:"revokedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+const (
+	ActivationToken         = "activation"
+	PasswordResetToken      = "passwordReset"
+	TwoFactorChallenge      = "twoFactorChallenge"
+	PasswordChangeChallenge = "passwordChange"
+)
+
+type VerificationToken struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	TokenHash string    `json:"-"`
+	Purpose   string    `json:"purpose"`
+	IsUsed    bool      `json:"isUsed"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
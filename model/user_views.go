@@ -0,0 +1,119 @@
+package model
+
+import "time"
+
+// PublicUserView is what other users see of a user. Contact details, coordinates and
+// account internals are left out.
+type PublicUserView struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Surname      string    `json:"surname"`
+	Username     string    `json:"username"`
+	BirthDate    time.Time `json:"birthDate"`
+	Description  string    `json:"description"`
+	ProfileImage string    `json:"profileImage"`
+	FriendIDs    []string  `json:"friendIds"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// SelfUserView is returned to the user the data belongs to.
+type SelfUserView struct {
+	PublicUserView
+	Email              string             `json:"email"`
+	MutedUserIDs       []string           `json:"mutedUserIds"`
+	UserType           string             `json:"userType"`
+	IsActivated        bool               `json:"isActivated"`
+	IsTwoFactorEnabled bool               `json:"isTwoFactorEnabled"`
+	MustChangePassword bool               `json:"mustChangePassword"`
+	ExternalIdentities []ExternalIdentity `json:"externalIdentities"`
+	Latitude           float64            `json:"latitude"`
+	Longitude          float64            `json:"longitude"`
+	UpdatedAt          time.Time          `json:"updatedAt"`
+	Version            int                `json:"version"`
+}
+
+// ProfileUserView is what other users see on the profile page of a user.
+type ProfileUserView struct {
+	PublicUserView
+	FriendCount       int  `json:"friendCount"`
+	MutualFriendCount int  `json:"mutualFriendCount"`
+	FollowerCount     int  `json:"followerCount"`
+	FollowingCount    int  `json:"followingCount"`
+	IsFollowing       bool `json:"isFollowing"`
+}
+
+// AdminUserView adds moderation state for the admin panel.
+type AdminUserView struct {
+	SelfUserView
+	IsBanned bool `json:"isBanned"`
+}
+
+type AdminUsersPageableResponse struct {
+	Users []AdminUserView `json:"users"`
+	Page  Page            `json:"page"`
+}
+
+func NewPublicUserView(user User) PublicUserView {
+	return PublicUserView{
+		ID:           user.ID,
+		Name:         user.Name,
+		Surname:      user.Surname,
+		Username:     user.Username,
+		BirthDate:    user.BirthDate,
+		Description:  user.Description,
+		ProfileImage: user.ProfileImage,
+		FriendIDs:    user.FriendIDs,
+		CreatedAt:    user.CreatedAt,
+	}
+}
+
+func NewSelfUserView(user User) SelfUserView {
+	return SelfUserView{
+		PublicUserView:     NewPublicUserView(user),
+		Email:              user.Email,
+		MutedUserIDs:       user.MutedUserIDs,
+		UserType:           user.UserType,
+		IsActivated:        user.IsActivated,
+		IsTwoFactorEnabled: user.IsTwoFactorEnabled,
+		MustChangePassword: user.MustChangePassword,
+		ExternalIdentities: user.ExternalIdentities,
+		Latitude:           user.Latitude,
+		Longitude:          user.Longitude,
+		UpdatedAt:          user.UpdatedAt,
+		Version:            user.Version,
+	}
+}
+
+func NewProfileUserView(profile UserProfile) ProfileUserView {
+	return ProfileUserView{
+		PublicUserView:    NewPublicUserView(profile.User),
+		FriendCount:       profile.FriendCount,
+		MutualFriendCount: profile.MutualFriendCount,
+		FollowerCount:     profile.FollowerCount,
+		FollowingCount:    profile.FollowingCount,
+		IsFollowing:       profile.IsFollowing,
+	}
+}
+
+func NewAdminUserView(user User) AdminUserView {
+	return AdminUserView{
+		SelfUserView: NewSelfUserView(user),
+		IsBanned:     user.IsBanned,
+	}
+}
+
+func NewPublicUserViews(users []User) []PublicUserView {
+	userViews := []PublicUserView{}
+	for _, user := range users {
+		userViews = append(userViews, NewPublicUserView(user))
+	}
+	return userViews
+}
+
+func NewAdminUserViews(users []User) []AdminUserView {
+	userViews := []AdminUserView{}
+	for _, user := range users {
+		userViews = append(userViews, NewAdminUserView(user))
+	}
+	return userViews
+}
@@ -5,23 +5,43 @@ import (
 	"time"
 )
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleSupport   = "support"
+	RoleAdmin     = "admin"
+)
+
+var Roles = []string{RoleUser, RoleModerator, RoleSupport, RoleAdmin}
+
 type User struct {
-	ID                   string    `json:"id"`
-	Name                 string    `json:"name"`
-	Surname              string    `json:"surname"`
-	Email                string    `json:"email"`
-	BirthDate            time.Time `json:"birthDate"`
-	Description          string    `json:"description"`
-	ProfileImage         string    `json:"profileImage"`
-	FriendRequestUserIDs []string  `json:"friendRequestUserIDs"`
-	FriendIDs            []string  `json:"friendIds"`
-	Password             string    `json:"password"`
-	UserType             string    `json:"userType"`
-	IsActivated          bool      `json:"isActivated"`
-	CreatedAt            time.Time `json:"createdAt"`
-	UpdatedAt            time.Time `json:"updatedAt"`
-	Latitude             float64   `json:"latitude"`
-	Longitude            float64   `json:"longitude"`
+	ID                 string             `json:"id"`
+	Name               string             `json:"name"`
+	Surname            string             `json:"surname"`
+	Username           string             `json:"username"`
+	Email              string             `json:"email"`
+	BirthDate          time.Time          `json:"birthDate"`
+	Description        string             `json:"description"`
+	ProfileImage       string             `json:"profileImage"`
+	FriendIDs          []string           `json:"friendIds"`
+	MutedUserIDs       []string           `json:"mutedUserIds"`
+	BlockedByUserIDs   []string           `json:"-"`
+	Password           string             `json:"-"`
+	UserType           string             `json:"userType"`
+	IsActivated        bool               `json:"isActivated"`
+	IsBanned           bool               `json:"isBanned"`
+	MustChangePassword bool               `json:"mustChangePassword"`
+	IsTwoFactorEnabled bool               `json:"isTwoFactorEnabled"`
+	TwoFactorSecret    string             `json:"-"`
+	TwoFactorLastStep  int64              `json:"-"`
+	RecoveryCodeHashes []string           `json:"-"`
+	TokenVersion       int                `json:"-"`
+	ExternalIdentities []ExternalIdentity `json:"externalIdentities"`
+	CreatedAt          time.Time          `json:"createdAt"`
+	UpdatedAt          time.Time          `json:"updatedAt"`
+	Latitude           float64            `json:"latitude"`
+	Longitude          float64            `json:"longitude"`
+	Version            int                `json:"version"`
 }
 
 type UserDTO struct {
@@ -51,9 +71,21 @@ type UserCredentialsDTO struct {
 	Password string `json:"password"`
 }
 
+// UpdateUserDTO.Version is the version of the user the client last read. When it is set the
+// update is rejected if the user changed since. An empty Username keeps the current one.
 type UpdateUserDTO struct {
+	Username     string `json:"username"`
 	Description  string `json:"description"`
 	ProfileImage string `json:"profileImage"`
+	Version      int    `json:"version"`
+}
+
+type UpdateUserRoleDTO struct {
+	Role string `json:"role"`
+}
+
+type BanUserDTO struct {
+	IsBanned bool `json:"isBanned"`
 }
 
 type ForgotPasswordDTO struct {
@@ -64,10 +96,27 @@ type ResetPasswordDTO struct {
 	Password string `json:"password"`
 }
 
+type PasswordChangeDTO struct {
+	ChallengeToken string `json:"challengeToken"`
+	Password       string `json:"password"`
+}
+
 type FriendRequestDTO struct {
 	UserID string `json:"userId"`
 }
 
+// UserProfile is a user with the counts of their profile page. MutualFriendCount is the number
+// of friends the user has in common with the viewer, IsFollowing tells whether the viewer
+// follows the user.
+type UserProfile struct {
+	User              User
+	FriendCount       int
+	MutualFriendCount int
+	FollowerCount     int
+	FollowingCount    int
+	IsFollowing       bool
+}
+
 type FriendRequestIDsDTO struct {
 	UserIDs []string `json:"userIds"`
 }
@@ -86,10 +135,16 @@ type GetNearUsersDTO struct {
 }
 
 type Token struct {
-	Token string `json:"token"`
+	Token                  string    `json:"token"`
+	RefreshToken           string    `json:"refreshToken"`
+	ExpiresAt              time.Time `json:"expiresAt"`
+	TwoFactorRequired      bool      `json:"twoFactorRequired,omitempty"`
+	PasswordChangeRequired bool      `json:"passwordChangeRequired,omitempty"`
+	ChallengeToken         string    `json:"challengeToken,omitempty"`
 }
 
 type CustomClaims struct {
-	UserType string `json:"userType"`
+	UserType     string `json:"userType"`
+	TokenVersion int    `json:"tokenVersion"`
 	jwt.StandardClaims
 }
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+func (repository *Repository) CreateAlbum(album model.Album) (*model.Album, error) {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	albumEntity := convertAlbumModelToAlbumEntity(album)
+	albumEntity.Version = 1
+
+	_, err := collection.InsertOne(ctx, albumEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetAlbum(albumEntity.ID)
+}
+
+func (repository *Repository) GetAlbum(albumID string) (*model.Album, error) {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur := collection.FindOne(ctx, bson.M{"id": albumID})
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, errors.AlbumNotFound
+	}
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	albumEntity := AlbumEntity{}
+	err := cur.Decode(&albumEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	album := convertAlbumEntityToAlbumModel(albumEntity)
+
+	return &album, nil
+}
+
+// GetUserAlbums returns the albums of the user, newest first.
+func (repository *Repository) GetUserAlbums(userID string) ([]model.Album, error) {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := options.Find()
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+
+	cur, err := collection.Find(ctx, bson.M{"userId": userID}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var albums []model.Album
+	for cur.Next(ctx) {
+		albumEntity := AlbumEntity{}
+		err := cur.Decode(&albumEntity)
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, convertAlbumEntityToAlbumModel(albumEntity))
+	}
+
+	return albums, nil
+}
+
+// UpdateAlbum replaces the album when it is still at album.Version and returns
+// errors.VersionConflict when another update got there first.
+func (repository *Repository) UpdateAlbum(albumID string, album model.Album) (*model.Album, error) {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	albumEntity := convertAlbumModelToAlbumEntity(album)
+	albumEntity.Version = album.Version + 1
+
+	err := replaceVersioned(ctx, collection, albumID, album.Version, albumEntity)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.AlbumNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetAlbum(albumID)
+}
+
+func (repository *Repository) DeleteAlbum(albumID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.DeleteOne(ctx, bson.M{"id": albumID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.AlbumNotFound
+	}
+
+	return nil
+}
+
+// AddAlbumItem appends the item to the album unless the album already holds it.
+func (repository *Repository) AddAlbumItem(albumID string, item model.AlbumItem) error {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"id":    albumID,
+		"items": bson.M{"$not": bson.M{"$elemMatch": bson.M{"postId": item.PostID, "mediaId": item.MediaID}}},
+	}
+	update := bson.M{
+		"$push": bson.M{"items": AlbumItemEntity{PostID: item.PostID, MediaID: item.MediaID, AddedAt: item.AddedAt}},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+func (repository *Repository) RemoveAlbumItem(albumID, postID, mediaID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$pull": bson.M{"items": bson.M{"postId": postID, "mediaId": mediaID}},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"id": albumID}, update)
+
+	return err
+}
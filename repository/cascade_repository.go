@@ -0,0 +1,554 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// UserEntity.FriendIDs has no bson tag, so the driver stores it under the lower cased field name.
+const userFriendIDsField = "friendids"
+
+// The functions below take the context of Repository.WithTransaction so that they can be
+// combined into one deletion.
+
+func (repository *Repository) GetPostIDsByUserID(ctx context.Context, userID string) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+
+	return distinctStrings(collection.Distinct(ctx, "id", bson.M{"userId": userID}))
+}
+
+func (repository *Repository) GetCommentIDsByUserID(ctx context.Context, userID string) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+
+	return distinctStrings(collection.Distinct(ctx, "id", bson.M{"userId": userID}))
+}
+
+// GetReplyCommentIDs returns the direct replies of the comments.
+func (repository *Repository) GetReplyCommentIDs(ctx context.Context, parentCommentIDs []string) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+
+	return distinctStrings(collection.Distinct(ctx, "id", bson.M{"parentCommentId": bson.M{"$in": nonNil(parentCommentIDs)}}))
+}
+
+func (repository *Repository) DeletePosts(ctx context.Context, postIDs []string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+
+	result, err := collection.DeleteMany(ctx, bson.M{"id": bson.M{"$in": nonNil(postIDs)}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func (repository *Repository) DeletePostComments(ctx context.Context, postIDs []string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+
+	result, err := collection.DeleteMany(ctx, bson.M{"postId": bson.M{"$in": nonNil(postIDs)}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func (repository *Repository) DeletePostRevisions(ctx context.Context, postIDs []string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("postRevisions")
+
+	result, err := collection.DeleteMany(ctx, bson.M{"postId": bson.M{"$in": nonNil(postIDs)}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// PullAlbumPosts removes the media items of the posts from every album that holds them.
+func (repository *Repository) PullAlbumPosts(ctx context.Context, postIDs []string) error {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+
+	filter := bson.M{"items.postId": bson.M{"$in": nonNil(postIDs)}}
+	update := bson.M{
+		"$pull": bson.M{"items": bson.M{"postId": bson.M{"$in": nonNil(postIDs)}}},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+
+	return err
+}
+
+func (repository *Repository) DeleteUserAlbums(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("albums")
+
+	_, err := collection.DeleteMany(ctx, bson.M{"userId": userID})
+
+	return err
+}
+
+// DeletePostNotifications removes the notifications about the posts and their comments.
+func (repository *Repository) DeletePostNotifications(ctx context.Context, postIDs []string) error {
+	collection := repository.MongoClient.Database("socium").Collection("notifications")
+
+	_, err := collection.DeleteMany(ctx, bson.M{"postId": bson.M{"$in": nonNil(postIDs)}})
+
+	return err
+}
+
+func (repository *Repository) DeleteCommentNotifications(ctx context.Context, commentIDs []string) error {
+	collection := repository.MongoClient.Database("socium").Collection("notifications")
+
+	_, err := collection.DeleteMany(ctx, bson.M{"commentId": bson.M{"$in": nonNil(commentIDs)}})
+
+	return err
+}
+
+// DeleteUserNotifications removes the notifications the user received and caused.
+func (repository *Repository) DeleteUserNotifications(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("notifications")
+
+	_, err := collection.DeleteMany(ctx, bson.M{"$or": bson.A{bson.M{"userId": userID}, bson.M{"actorId": userID}}})
+
+	return err
+}
+
+func (repository *Repository) DeleteComments(ctx context.Context, commentIDs []string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+
+	result, err := collection.DeleteMany(ctx, bson.M{"id": bson.M{"$in": nonNil(commentIDs)}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// PullPostComments removes the comment references from every post that holds them.
+func (repository *Repository) PullPostComments(ctx context.Context, commentIDs []string) error {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+
+	filter := bson.M{"commentIds": bson.M{"$in": nonNil(commentIDs)}}
+	update := bson.M{
+		"$pull": bson.M{"commentIds": bson.M{"$in": nonNil(commentIDs)}},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+
+	return err
+}
+
+// DeletePostReactions removes the reactions to the posts and to their comments.
+func (repository *Repository) DeletePostReactions(ctx context.Context, postIDs []string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+
+	result, err := collection.DeleteMany(ctx, bson.M{"postId": bson.M{"$in": nonNil(postIDs)}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func (repository *Repository) DeleteCommentReactions(ctx context.Context, commentIDs []string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+
+	filter := bson.M{"targetType": model.ReactionTargetComment, "targetId": bson.M{"$in": nonNil(commentIDs)}}
+
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func (repository *Repository) DeleteUserReactions(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+
+	_, err := collection.DeleteMany(ctx, bson.M{"userId": userID})
+
+	return err
+}
+
+// PullUserReferences removes the user from audiences, friend lists, mutes and blocks.
+func (repository *Repository) PullUserReferences(ctx context.Context, userID string) error {
+	posts := repository.MongoClient.Database("socium").Collection("posts")
+
+	postsUpdate := bson.M{
+		"$pull": bson.M{"audienceUserIds": userID},
+		"$inc":  bson.M{"version": 1},
+	}
+	_, err := posts.UpdateMany(ctx, bson.M{"audienceUserIds": userID}, postsUpdate)
+	if err != nil {
+		return err
+	}
+
+	users := repository.MongoClient.Database("socium").Collection("users")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{userFriendIDsField: userID},
+		bson.M{"mutedUserIds": userID},
+		bson.M{"blockedByUserIds": userID},
+	}}
+	update := bson.M{
+		"$pull": bson.M{userFriendIDsField: userID, "mutedUserIds": userID, "blockedByUserIds": userID},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	_, err = users.UpdateMany(ctx, filter, update)
+
+	return err
+}
+
+// DeleteUserFriendships removes the friendships and friend requests of the user in both directions.
+func (repository *Repository) DeleteUserFriendships(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+
+	filter := bson.M{"$or": bson.A{bson.M{"requesterId": userID}, bson.M{"addresseeId": userID}}}
+
+	_, err := collection.DeleteMany(ctx, filter)
+
+	return err
+}
+
+// DeleteUserFollows removes the follows of the user in both directions.
+func (repository *Repository) DeleteUserFollows(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+
+	filter := bson.M{"$or": bson.A{bson.M{"followerId": userID}, bson.M{"followeeId": userID}}}
+
+	_, err := collection.DeleteMany(ctx, filter)
+
+	return err
+}
+
+// DeleteUserSuggestions removes the suggestions made to the user. The user is left out of the
+// suggestions of others when they are read, and dropped from them at the next refresh.
+func (repository *Repository) DeleteUserSuggestions(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("suggestions")
+
+	_, err := collection.DeleteOne(ctx, bson.M{"userId": userID})
+
+	return err
+}
+
+// DeleteUserMedia removes the media documents of the user and returns the storage keys of
+// their files, which the caller deletes once the deletion is committed.
+func (repository *Repository) DeleteUserMedia(ctx context.Context, userID string) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("media")
+
+	cur, err := collection.Find(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for cur.Next(ctx) {
+		mediaEntity := MediaEntity{}
+		if err := cur.Decode(&mediaEntity); err != nil {
+			return nil, err
+		}
+		keys = append(keys, mediaEntity.Key, mediaEntity.ThumbnailKey)
+	}
+
+	_, err = collection.DeleteMany(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// DeletePostMedia removes the media documents of the posts that no other post or profile
+// uses and returns the storage keys of their files, which the caller deletes once the
+// deletion is committed.
+func (repository *Repository) DeletePostMedia(ctx context.Context, postIDs []string) ([]string, error) {
+	database := repository.MongoClient.Database("socium")
+
+	mediaIDs, err := distinctStrings(database.Collection("posts").Distinct(ctx, "media.mediaId", bson.M{"id": bson.M{"$in": nonNil(postIDs)}}))
+	if err != nil || len(mediaIDs) == 0 {
+		return nil, err
+	}
+
+	postMediaIDs, err := distinctStrings(database.Collection("posts").Distinct(ctx, "media.mediaId", bson.M{
+		"id":            bson.M{"$nin": nonNil(postIDs)},
+		"media.mediaId": bson.M{"$in": mediaIDs},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	profileMediaIDs, err := distinctStrings(database.Collection("users").Distinct(ctx, "profileImage", bson.M{"profileImage": bson.M{"$in": mediaIDs}}))
+	if err != nil {
+		return nil, err
+	}
+	usedMediaIDs := append(postMediaIDs, profileMediaIDs...)
+
+	filter := bson.M{"id": bson.M{"$in": mediaIDs, "$nin": nonNil(usedMediaIDs)}}
+	cur, err := database.Collection("media").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for cur.Next(ctx) {
+		mediaEntity := MediaEntity{}
+		if err := cur.Decode(&mediaEntity); err != nil {
+			return nil, err
+		}
+		keys = append(keys, mediaEntity.Key, mediaEntity.ThumbnailKey)
+	}
+
+	_, err = database.Collection("media").DeleteMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (repository *Repository) DeleteUserTokens(ctx context.Context, userID string) error {
+	for _, collectionName := range []string{"refreshTokens", "verificationTokens"} {
+		collection := repository.MongoClient.Database("socium").Collection(collectionName)
+
+		_, err := collection.DeleteMany(ctx, bson.M{"userId": userID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *Repository) DeleteUser(ctx context.Context, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+
+	_, err := collection.DeleteOne(ctx, bson.M{"id": userID})
+
+	return err
+}
+
+// GetOrphanPostIDs returns the posts of users that no longer exist and the posts whose
+// recovery period ended before deletedBefore.
+func (repository *Repository) GetOrphanPostIDs(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+
+	userIDs, err := repository.getAllIDs(ctx, "users")
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"userId": bson.M{"$nin": userIDs}},
+		bson.M{"deletedAt": bson.M{"$lt": deletedBefore}},
+	}}
+
+	return distinctStrings(collection.Distinct(ctx, "id", filter))
+}
+
+// GetOrphanCommentIDs returns the comments whose post, author or parent comment no longer exists.
+func (repository *Repository) GetOrphanCommentIDs(ctx context.Context) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+
+	userIDs, err := repository.getAllIDs(ctx, "users")
+	if err != nil {
+		return nil, err
+	}
+	postIDs, err := repository.getAllIDs(ctx, "posts")
+	if err != nil {
+		return nil, err
+	}
+	commentIDs, err := repository.getAllIDs(ctx, "comments")
+	if err != nil {
+		return nil, err
+	}
+
+	parentCommentIDs := bson.A{"", nil}
+	for _, commentID := range commentIDs {
+		parentCommentIDs = append(parentCommentIDs, commentID)
+	}
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"userId": bson.M{"$nin": userIDs}},
+		bson.M{"postId": bson.M{"$nin": postIDs}},
+		bson.M{"parentCommentId": bson.M{"$nin": parentCommentIDs}},
+	}}
+
+	return distinctStrings(collection.Distinct(ctx, "id", filter))
+}
+
+// DeleteOrphanPostRevisions removes the revisions of posts that no longer exist.
+func (repository *Repository) DeleteOrphanPostRevisions(ctx context.Context) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("postRevisions")
+
+	postIDs, err := repository.getAllIDs(ctx, "posts")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := collection.DeleteMany(ctx, bson.M{"postId": bson.M{"$nin": postIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// DeleteOrphanReactions removes the reactions whose author, post or comment no longer exists.
+func (repository *Repository) DeleteOrphanReactions(ctx context.Context) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+
+	userIDs, err := repository.getAllIDs(ctx, "users")
+	if err != nil {
+		return 0, err
+	}
+	postIDs, err := repository.getAllIDs(ctx, "posts")
+	if err != nil {
+		return 0, err
+	}
+	commentIDs, err := repository.getAllIDs(ctx, "comments")
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"userId": bson.M{"$nin": userIDs}},
+		bson.M{"postId": bson.M{"$nin": postIDs}},
+		bson.M{"targetType": model.ReactionTargetComment, "targetId": bson.M{"$nin": commentIDs}},
+	}}
+
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// DeleteOrphanFriendships removes the friendships of users that no longer exist.
+func (repository *Repository) DeleteOrphanFriendships(ctx context.Context) (int, error) {
+	userIDs, err := repository.getAllIDs(ctx, "users")
+	if err != nil {
+		return 0, err
+	}
+
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"requesterId": bson.M{"$nin": userIDs}},
+		bson.M{"addresseeId": bson.M{"$nin": userIDs}},
+	}}
+
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// DeleteOrphanFollows removes the follows of users that no longer exist.
+func (repository *Repository) DeleteOrphanFollows(ctx context.Context) (int, error) {
+	userIDs, err := repository.getAllIDs(ctx, "users")
+	if err != nil {
+		return 0, err
+	}
+
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"followerId": bson.M{"$nin": userIDs}},
+		bson.M{"followeeId": bson.M{"$nin": userIDs}},
+	}}
+
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// PullDanglingReferences removes references to comments and users that no longer exist
+// and returns the number of posts and users that changed.
+func (repository *Repository) PullDanglingReferences(ctx context.Context) (int, int, error) {
+	userIDs, err := repository.getAllIDs(ctx, "users")
+	if err != nil {
+		return 0, 0, err
+	}
+	commentIDs, err := repository.getAllIDs(ctx, "comments")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	posts := repository.MongoClient.Database("socium").Collection("posts")
+
+	postsFilter := bson.M{"$or": bson.A{
+		bson.M{"commentIds": bson.M{"$elemMatch": bson.M{"$nin": commentIDs}}},
+		bson.M{"audienceUserIds": bson.M{"$elemMatch": bson.M{"$nin": userIDs}}},
+	}}
+	postsUpdate := bson.M{
+		"$pull": bson.M{
+			"commentIds":      bson.M{"$nin": commentIDs},
+			"audienceUserIds": bson.M{"$nin": userIDs},
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	postsResult, err := posts.UpdateMany(ctx, postsFilter, postsUpdate)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	users := repository.MongoClient.Database("socium").Collection("users")
+
+	usersFilter := bson.M{"$or": bson.A{
+		bson.M{userFriendIDsField: bson.M{"$elemMatch": bson.M{"$nin": userIDs}}},
+		bson.M{"mutedUserIds": bson.M{"$elemMatch": bson.M{"$nin": userIDs}}},
+		bson.M{"blockedByUserIds": bson.M{"$elemMatch": bson.M{"$nin": userIDs}}},
+	}}
+	usersUpdate := bson.M{
+		"$pull": bson.M{
+			userFriendIDsField: bson.M{"$nin": userIDs},
+			"mutedUserIds":     bson.M{"$nin": userIDs},
+			"blockedByUserIds": bson.M{"$nin": userIDs},
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	usersResult, err := users.UpdateMany(ctx, usersFilter, usersUpdate)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(postsResult.ModifiedCount), int(usersResult.ModifiedCount), nil
+}
+
+func (repository *Repository) getAllIDs(ctx context.Context, collectionName string) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection(collectionName)
+
+	return distinctStrings(collection.Distinct(ctx, "id", bson.M{}))
+}
+
+func distinctStrings(values []interface{}, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			result = append(result, str)
+		}
+	}
+
+	return result, nil
+}
+
+// nonNil keeps $in and $nin queries valid, the server rejects a null array.
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
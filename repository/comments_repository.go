@@ -5,6 +5,7 @@ import (
 	"github.com/anilaydinn/socium-be/errors"
 	"github.com/anilaydinn/socium-be/model"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
@@ -82,15 +83,213 @@ func (repository *Repository) GetCommentsByIDList(commentIDs []string) ([]model.
 	return comments, nil
 }
 
-func (repository *Repository) GetCommentCount() (int, error) {
+// GetPostComments returns up to limit replies to the parent comment, or top level comments
+// when parentCommentID is empty, oldest first and starting after cursor when it is set.
+// Comments of hiddenUserIDs are left out.
+func (repository *Repository) GetPostComments(postID, parentCommentID string, hiddenUserIDs []string, cursor *model.Cursor, limit int) ([]model.Comment, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := options.Find()
+	options.SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "id", Value: 1}})
+	options.SetLimit(int64(limit))
+
+	filter := bson.M{"postId": postID, "parentCommentId": parentCommentID, "userId": bson.M{"$nin": nonNil(hiddenUserIDs)}}
+	if len(parentCommentID) == 0 {
+		// Comments stored before replies existed have no parentCommentId.
+		filter["parentCommentId"] = bson.M{"$in": bson.A{"", nil}}
+	}
+
+	if cursor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$gt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$gt": cursor.ID}},
+		}
+	}
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []model.Comment
+	for cur.Next(ctx) {
+		commentEntity := CommentEntity{}
+		err := cur.Decode(&commentEntity)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, convertCommentEntityToCommentModel(commentEntity))
+	}
+
+	return comments, nil
+}
+
+// GetPostCommentCounts returns the number of comments and replies of each post that has any,
+// leaving out comments of hiddenUserIDs.
+func (repository *Repository) GetPostCommentCounts(postIDs, hiddenUserIDs []string) (map[string]int, error) {
 	collection := repository.MongoClient.Database("socium").Collection("comments")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	commentCount, err := collection.CountDocuments(ctx, bson.M{})
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"postId": bson.M{"$in": nonNil(postIDs)}, "userId": bson.M{"$nin": nonNil(hiddenUserIDs)}}},
+		bson.M{"$group": bson.M{"_id": "$postId", "count": bson.M{"$sum": 1}}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	commentCounts := map[string]int{}
+	for cur.Next(ctx) {
+		commentCount := struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		}{}
+		err := cur.Decode(&commentCount)
+		if err != nil {
+			return nil, err
+		}
+		commentCounts[commentCount.ID] = commentCount.Count
+	}
+
+	return commentCounts, nil
+}
+
+// GetPostCommentPreviews returns up to limit top level comments of each post, oldest first,
+// leaving out comments of hiddenUserIDs. Comments are looked up post by post, so only limit
+// comments of each post are ever read.
+func (repository *Repository) GetPostCommentPreviews(postIDs, hiddenUserIDs []string, limit int) ([]model.Comment, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"id": bson.M{"$in": nonNil(postIDs)}}},
+		bson.M{"$project": bson.M{"_id": 0, "id": 1}},
+		bson.M{"$lookup": bson.M{
+			"from": "comments",
+			"let":  bson.M{"postId": "$id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"$expr":           bson.M{"$eq": bson.A{"$postId", "$$postId"}},
+					"parentCommentId": bson.M{"$in": bson.A{"", nil}},
+					"userId":          bson.M{"$nin": nonNil(hiddenUserIDs)},
+				}},
+				bson.M{"$sort": bson.D{{Key: "createdAt", Value: 1}, {Key: "id", Value: 1}}},
+				bson.M{"$limit": limit},
+			},
+			"as": "comments",
+		}},
+		bson.M{"$unwind": "$comments"},
+		bson.M{"$replaceRoot": bson.M{"newRoot": "$comments"}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []model.Comment
+	for cur.Next(ctx) {
+		commentEntity := CommentEntity{}
+		err := cur.Decode(&commentEntity)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, convertCommentEntityToCommentModel(commentEntity))
+	}
+
+	return comments, nil
+}
+
+// GetCommentReplyCounts returns the number of direct replies of each comment that has any,
+// leaving out replies of hiddenUserIDs.
+func (repository *Repository) GetCommentReplyCounts(commentIDs, hiddenUserIDs []string) (map[string]int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{
+			"parentCommentId": bson.M{"$in": nonNil(commentIDs)},
+			"userId":          bson.M{"$nin": nonNil(hiddenUserIDs)},
+		}},
+		bson.M{"$group": bson.M{"_id": "$parentCommentId", "count": bson.M{"$sum": 1}}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	replyCounts := map[string]int{}
+	for cur.Next(ctx) {
+		replyCount := struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		}{}
+		err := cur.Decode(&replyCount)
+		if err != nil {
+			return nil, err
+		}
+		replyCounts[replyCount.ID] = replyCount.Count
+	}
+
+	return replyCounts, nil
+}
+
+func (repository *Repository) UpdateComment(commentID string, comment model.Comment) (*model.Comment, error) {
+	collection := repository.MongoClient.Database("socium").Collection("comments")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": commentID}
+
+	commentEntity := convertCommentModelToCommentEntity(comment)
+
+	cur := collection.FindOneAndReplace(ctx, filter, commentEntity)
+
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	return repository.GetComment(commentID)
+}
+
+// GetCommentCount returns the number of comments on posts that are not deleted. Posts keep
+// the IDs of their comments, so they are counted without reading the comments, and comments
+// left behind by posts that no longer exist are not counted.
+func (repository *Repository) GetCommentCount() (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"deletedAt": nil}},
+		bson.M{"$group": bson.M{
+			"_id":   nil,
+			"count": bson.M{"$sum": bson.M{"$size": bson.M{"$ifNull": bson.A{"$commentIds", bson.A{}}}}},
+		}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return 0, err
 	}
 
-	return int(commentCount), nil
+	commentCount := struct {
+		Count int `bson:"count"`
+	}{}
+	if cur.Next(ctx) {
+		err := cur.Decode(&commentCount)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return commentCount.Count, nil
 }
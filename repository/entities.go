@@ -3,43 +3,176 @@ package repository
 import "time"
 
 type UserEntity struct {
-	ID                   string    `bson:"id"`
-	Name                 string    `bson:"name"`
-	Surname              string    `bson:"surname"`
-	Email                string    `bson:"email"`
-	BirthDate            time.Time `bson:"birthDate"`
-	Description          string    `bson:"description"`
-	ProfileImage         string    `bson:"profileImage"`
-	FriendRequestUserIDs []string  `bson:"friendRequestUserIDs"`
-	FriendIDs            []string  `json:"friendIds"`
-	Password             string    `bson:"password"`
-	UserType             string    `bson:"userType"`
-	IsActivated          bool      `bson:"isActivated"`
-	CreatedAt            time.Time `bson:"createdAt"`
-	UpdatedAt            time.Time `bson:"updatedAt"`
-	Latitude             float64   `bson:"latitude"`
-	Longitude            float64   `bson:"longitude"`
+	ID                 string                   `bson:"id"`
+	Name               string                   `bson:"name"`
+	Surname            string                   `bson:"surname"`
+	Username           string                   `bson:"username"`
+	Email              string                   `bson:"email"`
+	BirthDate          time.Time                `bson:"birthDate"`
+	Description        string                   `bson:"description"`
+	ProfileImage       string                   `bson:"profileImage"`
+	FriendIDs          []string                 `json:"friendIds"`
+	MutedUserIDs       []string                 `bson:"mutedUserIds"`
+	BlockedByUserIDs   []string                 `bson:"blockedByUserIds"`
+	Password           string                   `bson:"password"`
+	UserType           string                   `bson:"userType"`
+	IsActivated        bool                     `bson:"isActivated"`
+	IsBanned           bool                     `bson:"isBanned"`
+	MustChangePassword bool                     `bson:"mustChangePassword"`
+	IsTwoFactorEnabled bool                     `bson:"isTwoFactorEnabled"`
+	TwoFactorSecret    string                   `bson:"twoFactorSecret"`
+	TwoFactorLastStep  int64                    `bson:"twoFactorLastStep"`
+	RecoveryCodeHashes []string                 `bson:"recoveryCodeHashes"`
+	TokenVersion       int                      `bson:"tokenVersion"`
+	ExternalIdentities []ExternalIdentityEntity `bson:"externalIdentities"`
+	CreatedAt          time.Time                `bson:"createdAt"`
+	UpdatedAt          time.Time                `bson:"updatedAt"`
+	Latitude           float64                  `bson:"latitude"`
+	Longitude          float64                  `bson:"longitude"`
+	Location           *GeoPointEntity          `bson:"location,omitempty"`
+	Version            int                      `bson:"version"`
+}
+
+// GeoPointEntity is a GeoJSON point, longitude first.
+type GeoPointEntity struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"`
 }
 
 type PostEntity struct {
-	ID              string    `bson:"id"`
-	UserID          string    `bson:"userId"`
-	Description     string    `bson:"description"`
-	Image           string    `bson:"image"`
-	IsPrivate       bool      `bson:"isPrivate"`
-	WhoLikesUserIDs []string  `bson:"whoLikesUserIds"`
-	CommentIDs      []string  `bson:"commentIds"`
-	CreatedAt       time.Time `bson:"createdAt"`
-	UpdatedAt       time.Time `bson:"updatedAt"`
+	ID               string            `bson:"id"`
+	UserID           string            `bson:"userId"`
+	Description      string            `bson:"description"`
+	Image            string            `bson:"image"`
+	Media            []PostMediaEntity `bson:"media"`
+	Hashtags         []string          `bson:"hashtags"`
+	MentionedUserIDs []string          `bson:"mentionedUserIds"`
+	IsPrivate        bool              `bson:"isPrivate"`
+	Audience         string            `bson:"audience"`
+	AudienceUserIDs  []string          `bson:"audienceUserIds"`
+	CommentIDs       []string          `bson:"commentIds"`
+	IsEdited         bool              `bson:"isEdited"`
+	CreatedAt        time.Time         `bson:"createdAt"`
+	UpdatedAt        time.Time         `bson:"updatedAt"`
+	DeletedAt        *time.Time        `bson:"deletedAt,omitempty"`
+	Version          int               `bson:"version"`
+}
+
+type PostRevisionEntity struct {
+	ID              string            `bson:"id"`
+	PostID          string            `bson:"postId"`
+	Description     string            `bson:"description"`
+	Image           string            `bson:"image"`
+	Media           []PostMediaEntity `bson:"media"`
+	Audience        string            `bson:"audience"`
+	AudienceUserIDs []string          `bson:"audienceUserIds"`
+	CreatedAt       time.Time         `bson:"createdAt"`
+}
+
+type PostMediaEntity struct {
+	MediaID string `bson:"mediaId"`
+	Caption string `bson:"caption"`
+	AltText string `bson:"altText"`
 }
 
 type CommentEntity struct {
+	ID               string    `bson:"id"`
+	UserID           string    `bson:"userId"`
+	PostID           string    `bson:"postId"`
+	ParentCommentID  string    `bson:"parentCommentId"`
+	Content          string    `bson:"content"`
+	Hashtags         []string  `bson:"hashtags"`
+	MentionedUserIDs []string  `bson:"mentionedUserIds"`
+	IsEdited         bool      `bson:"isEdited"`
+	CreatedAt        time.Time `bson:"createdAt"`
+	UpdatedAt        time.Time `bson:"updatedAt"`
+}
+
+type ReactionEntity struct {
+	ID         string    `bson:"id"`
+	UserID     string    `bson:"userId"`
+	TargetType string    `bson:"targetType"`
+	TargetID   string    `bson:"targetId"`
+	PostID     string    `bson:"postId"`
+	Type       string    `bson:"type"`
+	CreatedAt  time.Time `bson:"createdAt"`
+}
+
+type MediaEntity struct {
+	ID                   string    `bson:"id"`
+	UserID               string    `bson:"userId"`
+	ContentType          string    `bson:"contentType"`
+	Size                 int       `bson:"size"`
+	Width                int       `bson:"width"`
+	Height               int       `bson:"height"`
+	Key                  string    `bson:"key"`
+	ThumbnailKey         string    `bson:"thumbnailKey"`
+	ThumbnailContentType string    `bson:"thumbnailContentType"`
+	CreatedAt            time.Time `bson:"createdAt"`
+}
+
+type AlbumEntity struct {
+	ID          string            `bson:"id"`
+	UserID      string            `bson:"userId"`
+	Name        string            `bson:"name"`
+	Description string            `bson:"description"`
+	Items       []AlbumItemEntity `bson:"items"`
+	CreatedAt   time.Time         `bson:"createdAt"`
+	UpdatedAt   time.Time         `bson:"updatedAt"`
+	Version     int               `bson:"version"`
+}
+
+type AlbumItemEntity struct {
+	PostID  string    `bson:"postId"`
+	MediaID string    `bson:"mediaId"`
+	AddedAt time.Time `bson:"addedAt"`
+}
+
+// FriendshipEntity.PairID is the same for both directions of a pair of users, so that a pair
+// has a single friendship.
+type FriendshipEntity struct {
+	ID          string     `bson:"id"`
+	PairID      string     `bson:"pairId"`
+	RequesterID string     `bson:"requesterId"`
+	AddresseeID string     `bson:"addresseeId"`
+	Status      string     `bson:"status"`
+	CreatedAt   time.Time  `bson:"createdAt"`
+	UpdatedAt   time.Time  `bson:"updatedAt"`
+	RespondedAt *time.Time `bson:"respondedAt"`
+}
+
+type FollowEntity struct {
+	ID         string    `bson:"id"`
+	FollowerID string    `bson:"followerId"`
+	FolloweeID string    `bson:"followeeId"`
+	CreatedAt  time.Time `bson:"createdAt"`
+}
+
+// UserSuggestionsEntity holds the precomputed friend suggestions of a user, best first.
+type UserSuggestionsEntity struct {
+	UserID      string             `bson:"userId"`
+	Suggestions []SuggestionEntity `bson:"suggestions"`
+	ComputedAt  time.Time          `bson:"computedAt"`
+}
+
+type SuggestionEntity struct {
+	UserID             string  `bson:"userId"`
+	Score              float64 `bson:"score"`
+	MutualFriendCount  int     `bson:"mutualFriendCount"`
+	SharedHashtagCount int     `bson:"sharedHashtagCount"`
+	InteractionCount   int     `bson:"interactionCount"`
+	IsNearby           bool    `bson:"isNearby"`
+}
+
+type NotificationEntity struct {
 	ID        string    `bson:"id"`
 	UserID    string    `bson:"userId"`
+	Type      string    `bson:"type"`
+	ActorID   string    `bson:"actorId"`
 	PostID    string    `bson:"postId"`
-	Content   string    `bson:"content"`
+	CommentID string    `bson:"commentId"`
+	IsRead    bool      `bson:"isRead"`
 	CreatedAt time.Time `bson:"createdAt"`
-	UpdatedAt time.Time `bson:"updatedAt"`
 }
 
 type ContactEntity struct {
@@ -49,3 +182,62 @@ type ContactEntity struct {
 	Email   string `bson:"email"`
 	Message string `bson:"message"`
 }
+
+type RefreshTokenEntity struct {
+	ID        string    `bson:"id"`
+	UserID    string    `bson:"userId"`
+	TokenHash string    `bson:"tokenHash"`
+	IsRevoked bool      `bson:"isRevoked"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+type RevokedTokenEntity struct {
+	TokenID   string    `bson:"tokenId"`
+	UserID    string    `bson:"userId"`
+	RevokedAt time.Time `bson:"revokedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+type VerificationTokenEntity struct {
+	ID        string    `bson:"id"`
+	UserID    string    `bson:"userId"`
+	TokenHash string    `bson:"tokenHash"`
+	Purpose   string    `bson:"purpose"`
+	IsUsed    bool      `bson:"isUsed"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+type LoginAttemptEntity struct {
+	Key          string    `bson:"key"`
+	FailedCount  int       `bson:"failedCount"`
+	LockedUntil  time.Time `bson:"lockedUntil"`
+	LastFailedAt time.Time `bson:"lastFailedAt"`
+}
+
+type AuditEventEntity struct {
+	ID          string    `bson:"id"`
+	Type        string    `bson:"type"`
+	Key         string    `bson:"key"`
+	UserID      string    `bson:"userId"`
+	IPAddress   string    `bson:"ipAddress"`
+	LockedUntil time.Time `bson:"lockedUntil"`
+	CreatedAt   time.Time `bson:"createdAt"`
+}
+
+type ExternalIdentityEntity struct {
+	Provider string    `bson:"provider"`
+	Subject  string    `bson:"subject"`
+	Email    string    `bson:"email"`
+	LinkedAt time.Time `bson:"linkedAt"`
+}
+
+type OAuthStateEntity struct {
+	ID           string    `bson:"id"`
+	StateHash    string    `bson:"stateHash"`
+	Provider     string    `bson:"provider"`
+	CodeVerifier string    `bson:"codeVerifier"`
+	ExpiresAt    time.Time `bson:"expiresAt"`
+	CreatedAt    time.Time `bson:"createdAt"`
+}
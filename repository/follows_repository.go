@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+func (repository *Repository) CreateFollow(follow model.Follow) error {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, convertFollowModelToFollowEntity(follow))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.AlreadyFollowing
+	}
+
+	return err
+}
+
+func (repository *Repository) DeleteFollow(followerID, followeeID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.DeleteOne(ctx, bson.M{"followerId": followerID, "followeeId": followeeID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.FollowNotFound
+	}
+
+	return nil
+}
+
+// DeleteFollowsBetween removes the follows between two users in both directions.
+func (repository *Repository) DeleteFollowsBetween(ctx context.Context, userID, otherUserID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"followerId": userID, "followeeId": otherUserID},
+		bson.M{"followerId": otherUserID, "followeeId": userID},
+	}}
+
+	_, err := collection.DeleteMany(ctx, filter)
+
+	return err
+}
+
+// IsFollowing tells whether followerID follows followeeID.
+func (repository *Repository) IsFollowing(followerID, followeeID string) (bool, error) {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := collection.CountDocuments(ctx, bson.M{"followerId": followerID, "followeeId": followeeID})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetFollowers returns the follows of the users following the user, newest first.
+func (repository *Repository) GetFollowers(userID string, cursor *model.Cursor, limit int) ([]model.Follow, error) {
+	return repository.getFollows(bson.M{"followeeId": userID}, cursor, limit)
+}
+
+// GetFollowing returns the follows of the users the user follows, newest first.
+func (repository *Repository) GetFollowing(userID string, cursor *model.Cursor, limit int) ([]model.Follow, error) {
+	return repository.getFollows(bson.M{"followerId": userID}, cursor, limit)
+}
+
+func (repository *Repository) getFollows(filter bson.M, cursor *model.Cursor, limit int) ([]model.Follow, error) {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := options.Find()
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+	options.SetLimit(int64(limit))
+
+	if cursor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$lt": cursor.ID}},
+		}
+	}
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var follows []model.Follow
+	for cur.Next(ctx) {
+		followEntity := FollowEntity{}
+		err := cur.Decode(&followEntity)
+		if err != nil {
+			return nil, err
+		}
+		follows = append(follows, convertFollowEntityToFollowModel(followEntity))
+	}
+
+	return follows, nil
+}
+
+// GetFollowingIDs returns the IDs of the users the user follows.
+func (repository *Repository) GetFollowingIDs(userID string) ([]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return distinctStrings(collection.Distinct(ctx, "followeeId", bson.M{"followerId": userID}))
+}
+
+// CountFollows returns how many users follow the user and how many users the user follows.
+func (repository *Repository) CountFollows(userID string) (int, int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("follows")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	followerCount, err := collection.CountDocuments(ctx, bson.M{"followeeId": userID})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	followingCount, err := collection.CountDocuments(ctx, bson.M{"followerId": userID})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(followerCount), int(followingCount), nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+// The friend lists kept on users in sync with the friendships are the edges of the friendship
+// graph, which $graphLookup walks without loading users into memory. It walks friendGraphView,
+// which only keeps the fields the walk needs, so the users collected at every hop stay small.
+const friendGraphView = "friendGraph"
+
+// GetMutualFriends returns the friends in friendIDs who are also friends of the user.
+func (repository *Repository) GetMutualFriends(friendIDs []string, userID string) ([]model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"id": bson.M{"$in": nonNil(friendIDs)}, userFriendIDsField: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	var users []model.User
+	for cur.Next(ctx) {
+		userEntity := UserEntity{}
+		err := cur.Decode(&userEntity)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, convertUserEntityToUserModel(userEntity))
+	}
+
+	return users, nil
+}
+
+func (repository *Repository) CountMutualFriends(friendIDs []string, userID string) (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := collection.CountDocuments(ctx, bson.M{"id": bson.M{"$in": nonNil(friendIDs)}, userFriendIDsField: userID})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// GetConnections returns up to limit users at most maxDepth friendships away from the user,
+// closest first, leaving out the user and hiddenUserIDs.
+func (repository *Repository) GetConnections(userID string, maxDepth int, hiddenUserIDs []string, limit int) ([]model.Connection, error) {
+	pipeline := append(connectionsPipeline(userID, maxDepth),
+		bson.D{{Key: "$match", Value: bson.M{"userId": bson.M{"$nin": append([]string{userID}, hiddenUserIDs...)}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "degree", Value: 1}, {Key: "userId", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	)
+
+	return repository.getConnections(pipeline)
+}
+
+// GetConnection returns how many friendships away from the user the target user is, and
+// errors.NotConnected when it is further than maxDepth.
+func (repository *Repository) GetConnection(userID, targetID string, maxDepth int) (*model.Connection, error) {
+	pipeline := append(connectionsPipeline(userID, maxDepth),
+		bson.D{{Key: "$match", Value: bson.M{"userId": targetID}}},
+	)
+
+	connections, err := repository.getConnections(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if len(connections) == 0 {
+		return nil, errors.NotConnected
+	}
+
+	return &connections[0], nil
+}
+
+// connectionsPipeline walks the friend lists from the user breadth first, so every user
+// reached comes out once with the smallest number of hops. Users who are not activated or
+// are banned are neither returned nor walked through.
+func connectionsPipeline(userID string, maxDepth int) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"id": userID}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, userFriendIDsField: 1}}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":                    friendGraphView,
+			"startWith":               "$" + userFriendIDsField,
+			"connectFromField":        userFriendIDsField,
+			"connectToField":          "id",
+			"as":                      "connections",
+			"maxDepth":                maxDepth - 1,
+			"depthField":              "depth",
+			"restrictSearchWithMatch": bson.M{"isActivated": true, "isBanned": bson.M{"$ne": true}},
+		}}},
+		{{Key: "$unwind", Value: "$connections"}},
+		{{Key: "$project", Value: bson.M{
+			"_id":    0,
+			"userId": "$connections.id",
+			"degree": bson.M{"$add": bson.A{"$connections.depth", 1}},
+		}}},
+	}
+}
+
+func (repository *Repository) getConnections(pipeline mongo.Pipeline) ([]model.Connection, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var connections []model.Connection
+	for cur.Next(ctx) {
+		result := struct {
+			UserID string `bson:"userId"`
+			Degree int    `bson:"degree"`
+		}{}
+		if err := cur.Decode(&result); err != nil {
+			return nil, err
+		}
+		connections = append(connections, model.Connection{UserID: result.UserID, Degree: result.Degree})
+	}
+
+	return connections, nil
+}
@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// CreateFriendRequest stores the pending friendship, replacing a declined or cancelled one
+// between the same users. It returns errors.FriendRequestExists when the users already have
+// another friendship.
+func (repository *Repository) CreateFriendRequest(friendship model.Friendship) error {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	friendshipEntity := convertFriendshipModelToFriendshipEntity(friendship)
+
+	// When the pair has a friendship in any other state the upsert inserts a second one,
+	// which the unique pair index rejects.
+	filter := bson.M{
+		"pairId": friendshipEntity.PairID,
+		"status": bson.M{"$in": bson.A{model.FriendshipDeclined, model.FriendshipCancelled}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"requesterId": friendshipEntity.RequesterID,
+			"addresseeId": friendshipEntity.AddresseeID,
+			"status":      friendshipEntity.Status,
+			"createdAt":   friendshipEntity.CreatedAt,
+			"updatedAt":   friendshipEntity.UpdatedAt,
+			"respondedAt": nil,
+		},
+		"$setOnInsert": bson.M{"id": friendshipEntity.ID},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.FriendRequestExists
+	}
+
+	return err
+}
+
+// GetFriendship returns the friendship between the users in either direction, or nil when
+// they have none.
+func (repository *Repository) GetFriendship(userID, otherUserID string) (*model.Friendship, error) {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur := collection.FindOne(ctx, bson.M{"pairId": getFriendshipPairID(userID, otherUserID)})
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	friendshipEntity := FriendshipEntity{}
+	err := cur.Decode(&friendshipEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	friendship := convertFriendshipEntityToFriendshipModel(friendshipEntity)
+
+	return &friendship, nil
+}
+
+// GetIncomingFriendRequests returns the pending friend requests sent to the user, newest first.
+func (repository *Repository) GetIncomingFriendRequests(userID string) ([]model.Friendship, error) {
+	return repository.getFriendships(bson.M{"addresseeId": userID, "status": model.FriendshipPending})
+}
+
+// GetOutgoingFriendRequests returns the pending friend requests the user sent, newest first.
+func (repository *Repository) GetOutgoingFriendRequests(userID string) ([]model.Friendship, error) {
+	return repository.getFriendships(bson.M{"requesterId": userID, "status": model.FriendshipPending})
+}
+
+// GetUserFriendshipsWithStatus returns the friendships of the user in both directions that
+// are in one of the statuses, newest first.
+func (repository *Repository) GetUserFriendshipsWithStatus(userID string, statuses []string) ([]model.Friendship, error) {
+	return repository.getFriendships(bson.M{
+		"$or":    bson.A{bson.M{"requesterId": userID}, bson.M{"addresseeId": userID}},
+		"status": bson.M{"$in": nonNil(statuses)},
+	})
+}
+
+// GetFriendshipsWithStatus returns every friendship in one of the statuses.
+func (repository *Repository) GetFriendshipsWithStatus(statuses []string) ([]model.Friendship, error) {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"status": bson.M{"$in": nonNil(statuses)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var friendships []model.Friendship
+	for cur.Next(ctx) {
+		friendshipEntity := FriendshipEntity{}
+		err := cur.Decode(&friendshipEntity)
+		if err != nil {
+			return nil, err
+		}
+		friendships = append(friendships, convertFriendshipEntityToFriendshipModel(friendshipEntity))
+	}
+
+	return friendships, nil
+}
+
+func (repository *Repository) getFriendships(filter bson.M) ([]model.Friendship, error) {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := options.Find()
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var friendships []model.Friendship
+	for cur.Next(ctx) {
+		friendshipEntity := FriendshipEntity{}
+		err := cur.Decode(&friendshipEntity)
+		if err != nil {
+			return nil, err
+		}
+		friendships = append(friendships, convertFriendshipEntityToFriendshipModel(friendshipEntity))
+	}
+
+	return friendships, nil
+}
+
+// The functions below take the context of Repository.WithTransaction so that a friendship
+// and the friend lists of its users change together.
+
+// UpdateFriendshipStatus moves the friend request of requesterID to addresseeID from one
+// status to another. It returns errors.FriendRequestNotFound when there is no such request
+// in the from status, so that a request is only answered once.
+func (repository *Repository) UpdateFriendshipStatus(ctx context.Context, requesterID, addresseeID, fromStatus, toStatus string, updatedAt time.Time) error {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+
+	filter := bson.M{"requesterId": requesterID, "addresseeId": addresseeID, "status": fromStatus}
+	update := bson.M{"$set": bson.M{"status": toStatus, "updatedAt": updatedAt, "respondedAt": updatedAt}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.FriendRequestNotFound
+	}
+
+	return nil
+}
+
+func (repository *Repository) DeleteFriendship(ctx context.Context, userID, friendID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+
+	filter := bson.M{"pairId": getFriendshipPairID(userID, friendID), "status": model.FriendshipAccepted}
+
+	_, err := collection.DeleteOne(ctx, filter)
+
+	return err
+}
+
+// AddFriendIDs adds the users to each other's friend lists, which the post audience checks
+// read instead of the friendships.
+func (repository *Repository) AddFriendIDs(ctx context.Context, userID, friendID string) error {
+	return repository.updateFriendIDs(ctx, userID, friendID, "$addToSet")
+}
+
+func (repository *Repository) RemoveFriendIDs(ctx context.Context, userID, friendID string) error {
+	return repository.updateFriendIDs(ctx, userID, friendID, "$pull")
+}
+
+func (repository *Repository) updateFriendIDs(ctx context.Context, userID, friendID, operator string) error {
+	if err := repository.updateUserArray(ctx, userID, operator, userFriendIDsField, friendID); err != nil {
+		return err
+	}
+
+	return repository.updateUserArray(ctx, friendID, operator, userFriendIDsField, userID)
+}
+
+// updateUserArray applies the array operator in place, so concurrent relation changes do not
+// overwrite each other. The version is bumped so that replacements read before fail.
+func (repository *Repository) updateUserArray(ctx context.Context, userID, operator, field, value string) error {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+
+	update := bson.M{
+		operator: bson.M{field: value},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"id": userID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.UserNotFound
+	}
+
+	return nil
+}
+
+// GetBlocks returns the blocks the user made, newest first.
+func (repository *Repository) GetBlocks(userID string) ([]model.Friendship, error) {
+	return repository.getFriendships(bson.M{"requesterId": userID, "status": model.FriendshipBlocked})
+}
+
+// BlockUser turns the friendship between the users into the block of RequesterID on
+// AddresseeID, whatever its state. It returns errors.UserNotFound when AddresseeID already
+// blocked RequesterID, as the blocked user cannot see the blocker.
+func (repository *Repository) BlockUser(ctx context.Context, friendship model.Friendship) error {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+
+	friendshipEntity := convertFriendshipModelToFriendshipEntity(friendship)
+
+	// A block of the other user is not matched, so the upsert is rejected by the unique pair index.
+	filter := bson.M{"pairId": friendshipEntity.PairID, "$or": bson.A{
+		bson.M{"status": bson.M{"$ne": model.FriendshipBlocked}},
+		bson.M{"requesterId": friendshipEntity.RequesterID},
+	}}
+	update := bson.M{
+		"$set": bson.M{
+			"requesterId": friendshipEntity.RequesterID,
+			"addresseeId": friendshipEntity.AddresseeID,
+			"status":      model.FriendshipBlocked,
+			"createdAt":   friendshipEntity.CreatedAt,
+			"updatedAt":   friendshipEntity.UpdatedAt,
+			"respondedAt": nil,
+		},
+		"$setOnInsert": bson.M{"id": friendshipEntity.ID},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.UserNotFound
+	}
+
+	return err
+}
+
+// UnblockUser removes the block of blockerID on blockedID, leaving the users without a friendship.
+func (repository *Repository) UnblockUser(ctx context.Context, blockerID, blockedID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("friendships")
+
+	filter := bson.M{"requesterId": blockerID, "addresseeId": blockedID, "status": model.FriendshipBlocked}
+
+	_, err := collection.DeleteOne(ctx, filter)
+
+	return err
+}
+
+// AddBlockedByUserID records on the blocked user who blocked them, which the post and
+// comment queries read to hide the blocker's content.
+func (repository *Repository) AddBlockedByUserID(ctx context.Context, blockedID, blockerID string) error {
+	return repository.updateUserArray(ctx, blockedID, "$addToSet", "blockedByUserIds", blockerID)
+}
+
+func (repository *Repository) RemoveBlockedByUserID(ctx context.Context, blockedID, blockerID string) error {
+	return repository.updateUserArray(ctx, blockedID, "$pull", "blockedByUserIds", blockerID)
+}
+
+// MigrateFriendships moves the friends and friend requests embedded in users to friendships,
+// makes friend lists mutual and without duplicates, and returns how many users were migrated.
+func (repository *Repository) MigrateFriendships() (int, error) {
+	users := repository.MongoClient.Database("socium").Collection("users")
+	friendships := repository.MongoClient.Database("socium").Collection("friendships")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Every user saved before friendships has the field, which is removed once it is migrated.
+	cur, err := users.Find(ctx, bson.M{"friendRequestUserIDs": bson.M{"$exists": true}})
+	if err != nil {
+		return 0, err
+	}
+
+	migratedUsers := 0
+	for cur.Next(ctx) {
+		legacyUser := struct {
+			ID                   string    `bson:"id"`
+			FriendIDs            []string  `bson:"friendids"`
+			FriendRequestUserIDs []string  `bson:"friendRequestUserIDs"`
+			UpdatedAt            time.Time `bson:"updatedAt"`
+		}{}
+		err := cur.Decode(&legacyUser)
+		if err != nil {
+			return migratedUsers, err
+		}
+
+		for _, friendID := range legacyUser.FriendIDs {
+			if friendID == legacyUser.ID {
+				continue
+			}
+			// A request between friends that was never cleared up is accepted.
+			filter := bson.M{"pairId": getFriendshipPairID(legacyUser.ID, friendID)}
+			update := bson.M{
+				"$set": bson.M{"status": model.FriendshipAccepted},
+				"$setOnInsert": bson.M{
+					"id":          friendID + legacyUser.ID,
+					"requesterId": friendID,
+					"addresseeId": legacyUser.ID,
+					"createdAt":   legacyUser.UpdatedAt,
+					"updatedAt":   legacyUser.UpdatedAt,
+					"respondedAt": legacyUser.UpdatedAt,
+				},
+			}
+			_, err := friendships.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+			if err != nil {
+				return migratedUsers, err
+			}
+
+			_, err = users.UpdateOne(ctx, bson.M{"id": friendID}, bson.M{
+				"$addToSet": bson.M{userFriendIDsField: legacyUser.ID},
+				"$inc":      bson.M{"version": 1},
+			})
+			if err != nil {
+				return migratedUsers, err
+			}
+		}
+
+		for _, requesterID := range legacyUser.FriendRequestUserIDs {
+			if requesterID == legacyUser.ID {
+				continue
+			}
+			filter := bson.M{"pairId": getFriendshipPairID(legacyUser.ID, requesterID)}
+			update := bson.M{"$setOnInsert": FriendshipEntity{
+				ID:          requesterID + legacyUser.ID,
+				PairID:      getFriendshipPairID(legacyUser.ID, requesterID),
+				RequesterID: requesterID,
+				AddresseeID: legacyUser.ID,
+				Status:      model.FriendshipPending,
+				CreatedAt:   legacyUser.UpdatedAt,
+				UpdatedAt:   legacyUser.UpdatedAt,
+			}}
+			_, err := friendships.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+			if err != nil {
+				return migratedUsers, err
+			}
+		}
+
+		// Accepting a request twice used to add the friend twice.
+		update := mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				userFriendIDsField: bson.M{"$setUnion": bson.A{bson.M{"$ifNull": bson.A{"$" + userFriendIDsField, bson.A{}}}, bson.A{}}},
+				"version":          bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+			}}},
+			{{Key: "$unset", Value: "friendRequestUserIDs"}},
+		}
+		_, err = users.UpdateOne(ctx, bson.M{"id": legacyUser.ID}, update)
+		if err != nil {
+			return migratedUsers, err
+		}
+		migratedUsers++
+	}
+
+	return migratedUsers, nil
+}
@@ -4,93 +4,228 @@ import "github.com/anilaydinn/socium-be/model"
 
 func convertUserModelToUserEntity(user model.User) UserEntity {
 	return UserEntity{
-		ID:                   user.ID,
-		Name:                 user.Name,
-		Surname:              user.Surname,
-		Email:                user.Email,
-		BirthDate:            user.BirthDate,
-		Description:          user.Description,
-		ProfileImage:         user.ProfileImage,
-		FriendRequestUserIDs: user.FriendRequestUserIDs,
-		FriendIDs:            user.FriendIDs,
-		Password:             user.Password,
-		UserType:             user.UserType,
-		IsActivated:          user.IsActivated,
-		CreatedAt:            user.CreatedAt,
-		UpdatedAt:            user.UpdatedAt,
-		Latitude:             user.Latitude,
-		Longitude:            user.Longitude,
+		ID:                 user.ID,
+		Name:               user.Name,
+		Surname:            user.Surname,
+		Username:           user.Username,
+		Email:              user.Email,
+		BirthDate:          user.BirthDate,
+		Description:        user.Description,
+		ProfileImage:       user.ProfileImage,
+		FriendIDs:          user.FriendIDs,
+		MutedUserIDs:       user.MutedUserIDs,
+		BlockedByUserIDs:   user.BlockedByUserIDs,
+		Password:           user.Password,
+		UserType:           user.UserType,
+		IsActivated:        user.IsActivated,
+		IsBanned:           user.IsBanned,
+		MustChangePassword: user.MustChangePassword,
+		IsTwoFactorEnabled: user.IsTwoFactorEnabled,
+		TwoFactorSecret:    user.TwoFactorSecret,
+		TwoFactorLastStep:  user.TwoFactorLastStep,
+		RecoveryCodeHashes: user.RecoveryCodeHashes,
+		TokenVersion:       user.TokenVersion,
+		ExternalIdentities: convertExternalIdentityModelsToExternalIdentityEntities(user.ExternalIdentities),
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+		Latitude:           user.Latitude,
+		Longitude:          user.Longitude,
+		Location:           getUserLocation(user.Latitude, user.Longitude),
+		Version:            user.Version,
 	}
 }
 
+// getUserLocation returns the point the users geo index is built on, or nil when the user
+// shared no valid coordinates, which are zero otherwise.
+func getUserLocation(latitude, longitude float64) *GeoPointEntity {
+	if (latitude == 0 && longitude == 0) || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return nil
+	}
+
+	return &GeoPointEntity{Type: "Point", Coordinates: []float64{longitude, latitude}}
+}
+
 func convertUserEntityToUserModel(userEntity UserEntity) model.User {
 	return model.User{
-		ID:                   userEntity.ID,
-		Name:                 userEntity.Name,
-		Surname:              userEntity.Surname,
-		Email:                userEntity.Email,
-		BirthDate:            userEntity.BirthDate,
-		Description:          userEntity.Description,
-		ProfileImage:         userEntity.ProfileImage,
-		FriendRequestUserIDs: userEntity.FriendRequestUserIDs,
-		FriendIDs:            userEntity.FriendIDs,
-		Password:             userEntity.Password,
-		UserType:             userEntity.UserType,
-		IsActivated:          userEntity.IsActivated,
-		CreatedAt:            userEntity.CreatedAt,
-		UpdatedAt:            userEntity.UpdatedAt,
-		Latitude:             userEntity.Latitude,
-		Longitude:            userEntity.Longitude,
+		ID:                 userEntity.ID,
+		Name:               userEntity.Name,
+		Surname:            userEntity.Surname,
+		Username:           userEntity.Username,
+		Email:              userEntity.Email,
+		BirthDate:          userEntity.BirthDate,
+		Description:        userEntity.Description,
+		ProfileImage:       userEntity.ProfileImage,
+		FriendIDs:          userEntity.FriendIDs,
+		MutedUserIDs:       userEntity.MutedUserIDs,
+		BlockedByUserIDs:   userEntity.BlockedByUserIDs,
+		Password:           userEntity.Password,
+		UserType:           userEntity.UserType,
+		IsActivated:        userEntity.IsActivated,
+		IsBanned:           userEntity.IsBanned,
+		MustChangePassword: userEntity.MustChangePassword,
+		IsTwoFactorEnabled: userEntity.IsTwoFactorEnabled,
+		TwoFactorSecret:    userEntity.TwoFactorSecret,
+		TwoFactorLastStep:  userEntity.TwoFactorLastStep,
+		RecoveryCodeHashes: userEntity.RecoveryCodeHashes,
+		TokenVersion:       userEntity.TokenVersion,
+		ExternalIdentities: convertExternalIdentityEntitiesToExternalIdentityModels(userEntity.ExternalIdentities),
+		CreatedAt:          userEntity.CreatedAt,
+		UpdatedAt:          userEntity.UpdatedAt,
+		Latitude:           userEntity.Latitude,
+		Longitude:          userEntity.Longitude,
+		Version:            userEntity.Version,
 	}
 }
 
 func convertPostModelToPostEntity(post model.Post) PostEntity {
+	audience := post.Audience
+	if len(audience) == 0 {
+		audience = model.GetLegacyAudience(post.IsPrivate)
+	}
+
 	return PostEntity{
-		ID:              post.ID,
-		UserID:          post.UserID,
-		Description:     post.Description,
-		Image:           post.Image,
-		IsPrivate:       post.IsPrivate,
-		WhoLikesUserIDs: post.WhoLikesUserIDs,
-		CommentIDs:      post.CommentIDs,
-		CreatedAt:       post.CreatedAt,
-		UpdatedAt:       post.UpdatedAt,
+		ID:               post.ID,
+		UserID:           post.UserID,
+		Description:      post.Description,
+		Image:            post.Image,
+		Media:            convertPostMediaModelsToPostMediaEntities(post.Media),
+		Hashtags:         post.Hashtags,
+		MentionedUserIDs: post.MentionedUserIDs,
+		IsPrivate:        audience != model.AudiencePublic,
+		Audience:         audience,
+		AudienceUserIDs:  post.AudienceUserIDs,
+		CommentIDs:       post.CommentIDs,
+		IsEdited:         post.IsEdited,
+		CreatedAt:        post.CreatedAt,
+		UpdatedAt:        post.UpdatedAt,
+		DeletedAt:        post.DeletedAt,
+		Version:          post.Version,
 	}
 }
 
 func convertPostEntityToPostModel(postEntity PostEntity) model.Post {
+	audience := postEntity.Audience
+	if len(audience) == 0 {
+		audience = model.GetLegacyAudience(postEntity.IsPrivate)
+	}
+
+	media := convertPostMediaEntitiesToPostMediaModels(postEntity.Media)
+	if len(media) == 0 && len(postEntity.Image) != 0 {
+		// Posts stored before media lists existed only have the image.
+		media = []model.PostMedia{{MediaID: postEntity.Image}}
+	}
+
 	return model.Post{
-		ID:              postEntity.ID,
-		UserID:          postEntity.UserID,
-		Description:     postEntity.Description,
-		Image:           postEntity.Image,
-		IsPrivate:       postEntity.IsPrivate,
-		WhoLikesUserIDs: postEntity.WhoLikesUserIDs,
-		CommentIDs:      postEntity.CommentIDs,
-		CreatedAt:       postEntity.CreatedAt,
-		UpdatedAt:       postEntity.UpdatedAt,
+		ID:               postEntity.ID,
+		UserID:           postEntity.UserID,
+		Description:      postEntity.Description,
+		Image:            postEntity.Image,
+		Media:            media,
+		Hashtags:         postEntity.Hashtags,
+		MentionedUserIDs: postEntity.MentionedUserIDs,
+		IsPrivate:        postEntity.IsPrivate,
+		Audience:         audience,
+		AudienceUserIDs:  postEntity.AudienceUserIDs,
+		CommentIDs:       postEntity.CommentIDs,
+		IsEdited:         postEntity.IsEdited,
+		CreatedAt:        postEntity.CreatedAt,
+		UpdatedAt:        postEntity.UpdatedAt,
+		DeletedAt:        postEntity.DeletedAt,
+		Version:          postEntity.Version,
+	}
+}
+
+func convertPostMediaModelsToPostMediaEntities(media []model.PostMedia) []PostMediaEntity {
+	var postMediaEntities []PostMediaEntity
+	for _, postMedia := range media {
+		postMediaEntities = append(postMediaEntities, PostMediaEntity{
+			MediaID: postMedia.MediaID,
+			Caption: postMedia.Caption,
+			AltText: postMedia.AltText,
+		})
+	}
+
+	return postMediaEntities
+}
+
+func convertPostMediaEntitiesToPostMediaModels(postMediaEntities []PostMediaEntity) []model.PostMedia {
+	var media []model.PostMedia
+	for _, postMediaEntity := range postMediaEntities {
+		media = append(media, model.PostMedia{
+			MediaID: postMediaEntity.MediaID,
+			Caption: postMediaEntity.Caption,
+			AltText: postMediaEntity.AltText,
+		})
+	}
+
+	return media
+}
+
+func convertPostRevisionModelToPostRevisionEntity(postRevision model.PostRevision) PostRevisionEntity {
+	return PostRevisionEntity{
+		ID:              postRevision.ID,
+		PostID:          postRevision.PostID,
+		Description:     postRevision.Description,
+		Image:           postRevision.Image,
+		Media:           convertPostMediaModelsToPostMediaEntities(postRevision.Media),
+		Audience:        postRevision.Audience,
+		AudienceUserIDs: postRevision.AudienceUserIDs,
+		CreatedAt:       postRevision.CreatedAt,
+	}
+}
+
+func convertPostRevisionEntityToPostRevisionModel(postRevisionEntity PostRevisionEntity) model.PostRevision {
+	return model.PostRevision{
+		ID:              postRevisionEntity.ID,
+		PostID:          postRevisionEntity.PostID,
+		Description:     postRevisionEntity.Description,
+		Image:           postRevisionEntity.Image,
+		Media:           convertPostMediaEntitiesToPostMediaModels(postRevisionEntity.Media),
+		Audience:        postRevisionEntity.Audience,
+		AudienceUserIDs: postRevisionEntity.AudienceUserIDs,
+		CreatedAt:       postRevisionEntity.CreatedAt,
 	}
 }
 
 func convertCommentModelToCommentEntity(comment model.Comment) CommentEntity {
 	return CommentEntity{
-		ID:        comment.ID,
-		UserID:    comment.UserID,
-		PostID:    comment.PostID,
-		Content:   comment.Content,
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+		ID:               comment.ID,
+		UserID:           comment.UserID,
+		PostID:           comment.PostID,
+		ParentCommentID:  comment.ParentCommentID,
+		Content:          comment.Content,
+		Hashtags:         comment.Hashtags,
+		MentionedUserIDs: comment.MentionedUserIDs,
+		IsEdited:         comment.IsEdited,
+		CreatedAt:        comment.CreatedAt,
+		UpdatedAt:        comment.UpdatedAt,
 	}
 }
 
 func convertCommentEntityToCommentModel(commentEntity CommentEntity) model.Comment {
 	return model.Comment{
-		ID:        commentEntity.ID,
-		UserID:    commentEntity.UserID,
-		PostID:    commentEntity.PostID,
-		Content:   commentEntity.Content,
-		CreatedAt: commentEntity.CreatedAt,
-		UpdatedAt: commentEntity.UpdatedAt,
+		ID:               commentEntity.ID,
+		UserID:           commentEntity.UserID,
+		PostID:           commentEntity.PostID,
+		ParentCommentID:  commentEntity.ParentCommentID,
+		Content:          commentEntity.Content,
+		Hashtags:         commentEntity.Hashtags,
+		MentionedUserIDs: commentEntity.MentionedUserIDs,
+		IsEdited:         commentEntity.IsEdited,
+		CreatedAt:        commentEntity.CreatedAt,
+		UpdatedAt:        commentEntity.UpdatedAt,
+	}
+}
+
+func convertReactionEntityToReactionModel(reactionEntity ReactionEntity) model.Reaction {
+	return model.Reaction{
+		ID:         reactionEntity.ID,
+		UserID:     reactionEntity.UserID,
+		TargetType: reactionEntity.TargetType,
+		TargetID:   reactionEntity.TargetID,
+		PostID:     reactionEntity.PostID,
+		Type:       reactionEntity.Type,
+		CreatedAt:  reactionEntity.CreatedAt,
 	}
 }
 
@@ -113,3 +248,313 @@ func convertContactEntityToContactModel(contactEntity ContactEntity) model.Conta
 		Message: contactEntity.Message,
 	}
 }
+
+func convertRefreshTokenModelToRefreshTokenEntity(refreshToken model.RefreshToken) RefreshTokenEntity {
+	return RefreshTokenEntity{
+		ID:        refreshToken.ID,
+		UserID:    refreshToken.UserID,
+		TokenHash: refreshToken.TokenHash,
+		IsRevoked: refreshToken.IsRevoked,
+		ExpiresAt: refreshToken.ExpiresAt,
+		CreatedAt: refreshToken.CreatedAt,
+	}
+}
+
+func convertRefreshTokenEntityToRefreshTokenModel(refreshTokenEntity RefreshTokenEntity) model.RefreshToken {
+	return model.RefreshToken{
+		ID:        refreshTokenEntity.ID,
+		UserID:    refreshTokenEntity.UserID,
+		TokenHash: refreshTokenEntity.TokenHash,
+		IsRevoked: refreshTokenEntity.IsRevoked,
+		ExpiresAt: refreshTokenEntity.ExpiresAt,
+		CreatedAt: refreshTokenEntity.CreatedAt,
+	}
+}
+
+func convertRevokedTokenModelToRevokedTokenEntity(revokedToken model.RevokedToken) RevokedTokenEntity {
+	return RevokedTokenEntity{
+		TokenID:   revokedToken.TokenID,
+		UserID:    revokedToken.UserID,
+		RevokedAt: revokedToken.RevokedAt,
+		ExpiresAt: revokedToken.ExpiresAt,
+	}
+}
+
+func convertVerificationTokenModelToVerificationTokenEntity(verificationToken model.VerificationToken) VerificationTokenEntity {
+	return VerificationTokenEntity{
+		ID:        verificationToken.ID,
+		UserID:    verificationToken.UserID,
+		TokenHash: verificationToken.TokenHash,
+		Purpose:   verificationToken.Purpose,
+		IsUsed:    verificationToken.IsUsed,
+		ExpiresAt: verificationToken.ExpiresAt,
+		CreatedAt: verificationToken.CreatedAt,
+	}
+}
+
+func convertVerificationTokenEntityToVerificationTokenModel(verificationTokenEntity VerificationTokenEntity) model.VerificationToken {
+	return model.VerificationToken{
+		ID:        verificationTokenEntity.ID,
+		UserID:    verificationTokenEntity.UserID,
+		TokenHash: verificationTokenEntity.TokenHash,
+		Purpose:   verificationTokenEntity.Purpose,
+		IsUsed:    verificationTokenEntity.IsUsed,
+		ExpiresAt: verificationTokenEntity.ExpiresAt,
+		CreatedAt: verificationTokenEntity.CreatedAt,
+	}
+}
+
+func convertLoginAttemptEntityToLoginAttemptModel(loginAttemptEntity LoginAttemptEntity) model.LoginAttempt {
+	return model.LoginAttempt{
+		Key:          loginAttemptEntity.Key,
+		FailedCount:  loginAttemptEntity.FailedCount,
+		LockedUntil:  loginAttemptEntity.LockedUntil,
+		LastFailedAt: loginAttemptEntity.LastFailedAt,
+	}
+}
+
+func convertAuditEventModelToAuditEventEntity(auditEvent model.AuditEvent) AuditEventEntity {
+	return AuditEventEntity{
+		ID:          auditEvent.ID,
+		Type:        auditEvent.Type,
+		Key:         auditEvent.Key,
+		UserID:      auditEvent.UserID,
+		IPAddress:   auditEvent.IPAddress,
+		LockedUntil: auditEvent.LockedUntil,
+		CreatedAt:   auditEvent.CreatedAt,
+	}
+}
+
+func convertAuditEventEntityToAuditEventModel(auditEventEntity AuditEventEntity) model.AuditEvent {
+	return model.AuditEvent{
+		ID:          auditEventEntity.ID,
+		Type:        auditEventEntity.Type,
+		Key:         auditEventEntity.Key,
+		UserID:      auditEventEntity.UserID,
+		IPAddress:   auditEventEntity.IPAddress,
+		LockedUntil: auditEventEntity.LockedUntil,
+		CreatedAt:   auditEventEntity.CreatedAt,
+	}
+}
+
+func convertExternalIdentityModelsToExternalIdentityEntities(externalIdentities []model.ExternalIdentity) []ExternalIdentityEntity {
+	var externalIdentityEntities []ExternalIdentityEntity
+	for _, externalIdentity := range externalIdentities {
+		externalIdentityEntities = append(externalIdentityEntities, ExternalIdentityEntity{
+			Provider: externalIdentity.Provider,
+			Subject:  externalIdentity.Subject,
+			Email:    externalIdentity.Email,
+			LinkedAt: externalIdentity.LinkedAt,
+		})
+	}
+	return externalIdentityEntities
+}
+
+func convertExternalIdentityEntitiesToExternalIdentityModels(externalIdentityEntities []ExternalIdentityEntity) []model.ExternalIdentity {
+	var externalIdentities []model.ExternalIdentity
+	for _, externalIdentityEntity := range externalIdentityEntities {
+		externalIdentities = append(externalIdentities, model.ExternalIdentity{
+			Provider: externalIdentityEntity.Provider,
+			Subject:  externalIdentityEntity.Subject,
+			Email:    externalIdentityEntity.Email,
+			LinkedAt: externalIdentityEntity.LinkedAt,
+		})
+	}
+	return externalIdentities
+}
+
+func convertOAuthStateModelToOAuthStateEntity(oauthState model.OAuthState) OAuthStateEntity {
+	return OAuthStateEntity{
+		ID:           oauthState.ID,
+		StateHash:    oauthState.StateHash,
+		Provider:     oauthState.Provider,
+		CodeVerifier: oauthState.CodeVerifier,
+		ExpiresAt:    oauthState.ExpiresAt,
+		CreatedAt:    oauthState.CreatedAt,
+	}
+}
+
+func convertOAuthStateEntityToOAuthStateModel(oauthStateEntity OAuthStateEntity) model.OAuthState {
+	return model.OAuthState{
+		ID:           oauthStateEntity.ID,
+		StateHash:    oauthStateEntity.StateHash,
+		Provider:     oauthStateEntity.Provider,
+		CodeVerifier: oauthStateEntity.CodeVerifier,
+		ExpiresAt:    oauthStateEntity.ExpiresAt,
+		CreatedAt:    oauthStateEntity.CreatedAt,
+	}
+}
+
+func convertMediaModelToMediaEntity(media model.Media) MediaEntity {
+	return MediaEntity{
+		ID:                   media.ID,
+		UserID:               media.UserID,
+		ContentType:          media.ContentType,
+		Size:                 media.Size,
+		Width:                media.Width,
+		Height:               media.Height,
+		Key:                  media.Key,
+		ThumbnailKey:         media.ThumbnailKey,
+		ThumbnailContentType: media.ThumbnailContentType,
+		CreatedAt:            media.CreatedAt,
+	}
+}
+
+func convertMediaEntityToMediaModel(mediaEntity MediaEntity) model.Media {
+	return model.Media{
+		ID:                   mediaEntity.ID,
+		UserID:               mediaEntity.UserID,
+		ContentType:          mediaEntity.ContentType,
+		Size:                 mediaEntity.Size,
+		Width:                mediaEntity.Width,
+		Height:               mediaEntity.Height,
+		Key:                  mediaEntity.Key,
+		ThumbnailKey:         mediaEntity.ThumbnailKey,
+		ThumbnailContentType: mediaEntity.ThumbnailContentType,
+		URL:                  "/api/media/" + mediaEntity.ID,
+		ThumbnailURL:         "/api/media/" + mediaEntity.ID + "/thumbnail",
+		CreatedAt:            mediaEntity.CreatedAt,
+	}
+}
+
+func convertAlbumModelToAlbumEntity(album model.Album) AlbumEntity {
+	var albumItemEntities []AlbumItemEntity
+	for _, item := range album.Items {
+		albumItemEntities = append(albumItemEntities, AlbumItemEntity{
+			PostID:  item.PostID,
+			MediaID: item.MediaID,
+			AddedAt: item.AddedAt,
+		})
+	}
+
+	return AlbumEntity{
+		ID:          album.ID,
+		UserID:      album.UserID,
+		Name:        album.Name,
+		Description: album.Description,
+		Items:       albumItemEntities,
+		CreatedAt:   album.CreatedAt,
+		UpdatedAt:   album.UpdatedAt,
+		Version:     album.Version,
+	}
+}
+
+func convertAlbumEntityToAlbumModel(albumEntity AlbumEntity) model.Album {
+	var items []model.AlbumItem
+	for _, itemEntity := range albumEntity.Items {
+		items = append(items, model.AlbumItem{
+			PostID:  itemEntity.PostID,
+			MediaID: itemEntity.MediaID,
+			AddedAt: itemEntity.AddedAt,
+		})
+	}
+
+	return model.Album{
+		ID:          albumEntity.ID,
+		UserID:      albumEntity.UserID,
+		Name:        albumEntity.Name,
+		Description: albumEntity.Description,
+		Items:       items,
+		CreatedAt:   albumEntity.CreatedAt,
+		UpdatedAt:   albumEntity.UpdatedAt,
+		Version:     albumEntity.Version,
+	}
+}
+
+func convertFriendshipModelToFriendshipEntity(friendship model.Friendship) FriendshipEntity {
+	return FriendshipEntity{
+		ID:          friendship.ID,
+		PairID:      getFriendshipPairID(friendship.RequesterID, friendship.AddresseeID),
+		RequesterID: friendship.RequesterID,
+		AddresseeID: friendship.AddresseeID,
+		Status:      friendship.Status,
+		CreatedAt:   friendship.CreatedAt,
+		UpdatedAt:   friendship.UpdatedAt,
+		RespondedAt: friendship.RespondedAt,
+	}
+}
+
+func convertFriendshipEntityToFriendshipModel(friendshipEntity FriendshipEntity) model.Friendship {
+	return model.Friendship{
+		ID:          friendshipEntity.ID,
+		RequesterID: friendshipEntity.RequesterID,
+		AddresseeID: friendshipEntity.AddresseeID,
+		Status:      friendshipEntity.Status,
+		CreatedAt:   friendshipEntity.CreatedAt,
+		UpdatedAt:   friendshipEntity.UpdatedAt,
+		RespondedAt: friendshipEntity.RespondedAt,
+	}
+}
+
+func getFriendshipPairID(userID, otherUserID string) string {
+	if userID > otherUserID {
+		userID, otherUserID = otherUserID, userID
+	}
+	return userID + ":" + otherUserID
+}
+
+func convertFollowModelToFollowEntity(follow model.Follow) FollowEntity {
+	return FollowEntity{
+		ID:         follow.ID,
+		FollowerID: follow.FollowerID,
+		FolloweeID: follow.FolloweeID,
+		CreatedAt:  follow.CreatedAt,
+	}
+}
+
+func convertFollowEntityToFollowModel(followEntity FollowEntity) model.Follow {
+	return model.Follow{
+		ID:         followEntity.ID,
+		FollowerID: followEntity.FollowerID,
+		FolloweeID: followEntity.FolloweeID,
+		CreatedAt:  followEntity.CreatedAt,
+	}
+}
+
+func convertSuggestionModelToSuggestionEntity(suggestion model.Suggestion) SuggestionEntity {
+	return SuggestionEntity{
+		UserID:             suggestion.UserID,
+		Score:              suggestion.Score,
+		MutualFriendCount:  suggestion.MutualFriendCount,
+		SharedHashtagCount: suggestion.SharedHashtagCount,
+		InteractionCount:   suggestion.InteractionCount,
+		IsNearby:           suggestion.IsNearby,
+	}
+}
+
+func convertSuggestionEntityToSuggestionModel(suggestionEntity SuggestionEntity) model.Suggestion {
+	return model.Suggestion{
+		UserID:             suggestionEntity.UserID,
+		Score:              suggestionEntity.Score,
+		MutualFriendCount:  suggestionEntity.MutualFriendCount,
+		SharedHashtagCount: suggestionEntity.SharedHashtagCount,
+		InteractionCount:   suggestionEntity.InteractionCount,
+		IsNearby:           suggestionEntity.IsNearby,
+	}
+}
+
+func convertNotificationModelToNotificationEntity(notification model.Notification) NotificationEntity {
+	return NotificationEntity{
+		ID:        notification.ID,
+		UserID:    notification.UserID,
+		Type:      notification.Type,
+		ActorID:   notification.ActorID,
+		PostID:    notification.PostID,
+		CommentID: notification.CommentID,
+		IsRead:    notification.IsRead,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+func convertNotificationEntityToNotificationModel(notificationEntity NotificationEntity) model.Notification {
+	return model.Notification{
+		ID:        notificationEntity.ID,
+		UserID:    notificationEntity.UserID,
+		Type:      notificationEntity.Type,
+		ActorID:   notificationEntity.ActorID,
+		PostID:    notificationEntity.PostID,
+		CommentID: notificationEntity.CommentID,
+		IsRead:    notificationEntity.IsRead,
+		CreatedAt: notificationEntity.CreatedAt,
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// GetLoginAttempt returns nil without an error when nothing was tracked for the key yet.
+func (repository *Repository) GetLoginAttempt(key string) (*model.LoginAttempt, error) {
+	collection := repository.MongoClient.Database("socium").Collection("loginAttempts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"key": key}
+
+	cur := collection.FindOne(ctx, filter)
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	loginAttemptEntity := LoginAttemptEntity{}
+	err := cur.Decode(&loginAttemptEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	loginAttempt := convertLoginAttemptEntityToLoginAttemptModel(loginAttemptEntity)
+
+	return &loginAttempt, nil
+}
+
+// IncrementLoginAttempt counts a failure for the key and returns the attempt after the update.
+// Failures are started over when the key is not locked and the last one is older than staleBefore.
+// Both steps are single updates, so concurrent failures are never lost.
+func (repository *Repository) IncrementLoginAttempt(key string, now, staleBefore time.Time) (*model.LoginAttempt, error) {
+	collection := repository.MongoClient.Database("socium").Collection("loginAttempts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	staleFilter := bson.M{"key": key, "lockedUntil": bson.M{"$lt": now}, "lastFailedAt": bson.M{"$lt": staleBefore}}
+	reset := bson.M{"$set": bson.M{"failedCount": 0, "lockedUntil": time.Time{}}}
+
+	_, err := collection.UpdateOne(ctx, staleFilter, reset)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$inc":         bson.M{"failedCount": 1},
+		"$set":         bson.M{"lastFailedAt": now},
+		"$setOnInsert": bson.M{"lockedUntil": time.Time{}},
+	}
+	options := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	loginAttemptEntity := LoginAttemptEntity{}
+	err = collection.FindOneAndUpdate(ctx, bson.M{"key": key}, update, options).Decode(&loginAttemptEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	loginAttempt := convertLoginAttemptEntityToLoginAttemptModel(loginAttemptEntity)
+
+	return &loginAttempt, nil
+}
+
+// LockLoginAttempt locks the key until lockedUntil unless it is already locked for longer.
+// It reports whether the lock was set.
+func (repository *Repository) LockLoginAttempt(key string, lockedUntil time.Time) (bool, error) {
+	collection := repository.MongoClient.Database("socium").Collection("loginAttempts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"key": key, "lockedUntil": bson.M{"$lt": lockedUntil}}
+	update := bson.M{"$set": bson.M{"lockedUntil": lockedUntil}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// DeleteLoginAttempt forgets the failures of the key and returns what was deleted, or nil
+// without an error when nothing was tracked.
+func (repository *Repository) DeleteLoginAttempt(key string) (*model.LoginAttempt, error) {
+	collection := repository.MongoClient.Database("socium").Collection("loginAttempts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"key": key}
+
+	loginAttemptEntity := LoginAttemptEntity{}
+	err := collection.FindOneAndDelete(ctx, filter).Decode(&loginAttemptEntity)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loginAttempt := convertLoginAttemptEntityToLoginAttemptModel(loginAttemptEntity)
+
+	return &loginAttempt, nil
+}
+
+func (repository *Repository) CreateAuditEvent(auditEvent model.AuditEvent) error {
+	collection := repository.MongoClient.Database("socium").Collection("auditEvents")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	auditEventEntity := convertAuditEventModelToAuditEventEntity(auditEvent)
+
+	_, err := collection.InsertOne(ctx, auditEventEntity)
+
+	return err
+}
+
+func (repository *Repository) GetAuditEvents() ([]model.AuditEvent, error) {
+	collection := repository.MongoClient.Database("socium").Collection("auditEvents")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+
+	options := options.Find()
+	options.SetSort(bson.M{"createdAt": -1})
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	auditEvents := []model.AuditEvent{}
+	for cur.Next(ctx) {
+		auditEventEntity := AuditEventEntity{}
+		err := cur.Decode(&auditEventEntity)
+		if err != nil {
+			return nil, err
+		}
+		auditEvents = append(auditEvents, convertAuditEventEntityToAuditEventModel(auditEventEntity))
+	}
+
+	return auditEvents, nil
+}
+
+func (repository *Repository) DeleteAuditEvents() error {
+	collection := repository.MongoClient.Database("socium").Collection("auditEvents")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.DeleteMany(ctx, bson.M{})
+
+	return err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+func (repository *Repository) CreateMedia(media model.Media) (*model.Media, error) {
+	collection := repository.MongoClient.Database("socium").Collection("media")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mediaEntity := convertMediaModelToMediaEntity(media)
+
+	_, err := collection.InsertOne(ctx, mediaEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetMedia(mediaEntity.ID)
+}
+
+func (repository *Repository) GetMedia(mediaID string) (*model.Media, error) {
+	collection := repository.MongoClient.Database("socium").Collection("media")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur := collection.FindOne(ctx, bson.M{"id": mediaID})
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, errors.MediaNotFound
+	}
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	mediaEntity := MediaEntity{}
+	err := cur.Decode(&mediaEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	media := convertMediaEntityToMediaModel(mediaEntity)
+
+	return &media, nil
+}
+
+func (repository *Repository) GetMediaByIDList(mediaIDs []string) ([]model.Media, error) {
+	collection := repository.MongoClient.Database("socium").Collection("media")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"id": bson.M{"$in": nonNil(mediaIDs)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var media []model.Media
+	for cur.Next(ctx) {
+		mediaEntity := MediaEntity{}
+		if err := cur.Decode(&mediaEntity); err != nil {
+			return nil, err
+		}
+		media = append(media, convertMediaEntityToMediaModel(mediaEntity))
+	}
+
+	return media, nil
+}
+
+// GetInlineImages returns the images of the collection that are stored in imageField itself
+// instead of being a media ID. ownerField holds the ID of the user the image belongs to.
+func (repository *Repository) GetInlineImages(collectionName, imageField, ownerField string) ([]model.InlineImage, error) {
+	collection := repository.MongoClient.Database("socium").Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Media IDs are 32 characters long, anything longer is image data.
+	filter := bson.M{"$expr": bson.M{"$gt": bson.A{bson.M{"$strLenBytes": bson.M{"$ifNull": bson.A{"$" + imageField, ""}}}, 32}}}
+
+	cur, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var inlineImages []model.InlineImage
+	for cur.Next(ctx) {
+		document := bson.M{}
+		if err := cur.Decode(&document); err != nil {
+			return nil, err
+		}
+
+		id, _ := document["id"].(string)
+		userID, _ := document[ownerField].(string)
+		data, _ := document[imageField].(string)
+		inlineImages = append(inlineImages, model.InlineImage{ID: id, UserID: userID, Data: data})
+	}
+
+	return inlineImages, nil
+}
+
+// ReplaceInlineImage sets the media ID in place of the image data, unless the image was
+// changed in the meantime.
+func (repository *Repository) ReplaceInlineImage(collectionName, imageField, id, data, mediaID string) error {
+	collection := repository.MongoClient.Database("socium").Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": id, imageField: data}
+	update := bson.M{
+		"$set": bson.M{imageField: mediaID},
+		"$inc": bson.M{"version": 1},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// IsProfileImage reports whether a user has the media as profile image.
+func (repository *Repository) IsProfileImage(mediaID string) (bool, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := collection.CountDocuments(ctx, bson.M{"profileImage": mediaID}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
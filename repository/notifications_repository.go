@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+func (repository *Repository) CreateNotifications(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	collection := repository.MongoClient.Database("socium").Collection("notifications")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var documents []interface{}
+	for _, notification := range notifications {
+		documents = append(documents, convertNotificationModelToNotificationEntity(notification))
+	}
+
+	_, err := collection.InsertMany(ctx, documents)
+
+	return err
+}
+
+// GetNotifications returns the notifications of the user, newest first.
+func (repository *Repository) GetNotifications(userID string, cursor *model.Cursor, limit int) ([]model.Notification, error) {
+	collection := repository.MongoClient.Database("socium").Collection("notifications")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := options.Find()
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+	options.SetLimit(int64(limit))
+
+	filter := bson.M{"userId": userID}
+	if cursor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$lt": cursor.ID}},
+		}
+	}
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []model.Notification
+	for cur.Next(ctx) {
+		notificationEntity := NotificationEntity{}
+		err := cur.Decode(&notificationEntity)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, convertNotificationEntityToNotificationModel(notificationEntity))
+	}
+
+	return notifications, nil
+}
+
+func (repository *Repository) MarkNotificationsRead(userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("notifications")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateMany(ctx, bson.M{"userId": userID, "isRead": false}, bson.M{"$set": bson.M{"isRead": true}})
+
+	return err
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+func (repository *Repository) CreateOAuthState(oauthState model.OAuthState) error {
+	collection := repository.MongoClient.Database("socium").Collection("oauthStates")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oauthStateEntity := convertOAuthStateModelToOAuthStateEntity(oauthState)
+
+	_, err := collection.InsertOne(ctx, oauthStateEntity)
+
+	return err
+}
+
+// ConsumeOAuthState removes the state while reading it, so a callback cannot be replayed.
+func (repository *Repository) ConsumeOAuthState(stateHash string) (*model.OAuthState, error) {
+	collection := repository.MongoClient.Database("socium").Collection("oauthStates")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"stateHash": stateHash}
+
+	cur := collection.FindOneAndDelete(ctx, filter)
+
+	if cur.Err() != nil {
+		return nil, errors.InvalidOAuthState
+	}
+
+	oauthStateEntity := OAuthStateEntity{}
+	err := cur.Decode(&oauthStateEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	oauthState := convertOAuthStateEntityToOAuthStateModel(oauthStateEntity)
+
+	return &oauthState, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+func (repository *Repository) CreatePostRevision(postRevision model.PostRevision) error {
+	collection := repository.MongoClient.Database("socium").Collection("postRevisions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	postRevisionEntity := convertPostRevisionModelToPostRevisionEntity(postRevision)
+
+	_, err := collection.InsertOne(ctx, postRevisionEntity)
+
+	return err
+}
+
+func (repository *Repository) GetPostRevisions(postID string) ([]model.PostRevision, error) {
+	collection := repository.MongoClient.Database("socium").Collection("postRevisions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"postId": postID}
+
+	options := options.Find()
+	options.SetSort(bson.M{"createdAt": -1})
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	postRevisions := []model.PostRevision{}
+	for cur.Next(ctx) {
+		postRevisionEntity := PostRevisionEntity{}
+		err := cur.Decode(&postRevisionEntity)
+		if err != nil {
+			return nil, err
+		}
+		postRevisions = append(postRevisions, convertPostRevisionEntityToPostRevisionModel(postRevisionEntity))
+	}
+
+	return postRevisions, nil
+}
@@ -5,7 +5,9 @@ import (
 	"github.com/anilaydinn/socium-be/errors"
 	"github.com/anilaydinn/socium-be/model"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"sort"
 	"time"
 )
 
@@ -15,6 +17,8 @@ func (repository *Repository) CreatePost(post model.Post) (*model.Post, error) {
 	defer cancel()
 
 	postEntity := convertPostModelToPostEntity(post)
+	// Versions start at 1, so a client sending version 0 means it did not read one.
+	postEntity.Version = 1
 
 	_, err := collection.InsertOne(ctx, postEntity)
 
@@ -54,19 +58,33 @@ func (repository *Repository) GetPost(postID string) (*model.Post, error) {
 	return &post, nil
 }
 
-func (repository *Repository) GetPosts(userID string, isHomePage bool, friendIDs []string) ([]model.Post, error) {
+// GetPosts returns up to limit posts, newest first, starting after cursor when it is set.
+// When viewer is set only the posts whose audience includes the viewer are returned.
+func (repository *Repository) GetPosts(viewer *model.User, userID string, isHomePage bool, friendIDs []string, cursor *model.Cursor, limit int) ([]model.Post, error) {
 	collection := repository.MongoClient.Database("socium").Collection("posts")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	options := options.Find()
-	options.SetSort(bson.M{"createdAt": -1})
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+	options.SetLimit(int64(limit))
 
-	var filter bson.M
+	filter := bson.M{"deletedAt": nil}
 	if len(friendIDs) > 0 && isHomePage {
-		filter = bson.M{"userId": bson.M{"$in": friendIDs}}
+		filter["userId"] = bson.M{"$in": friendIDs}
 	} else if !isHomePage {
-		filter = bson.M{"userId": userID}
+		filter["userId"] = userID
+	}
+
+	if cursor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$lt": cursor.ID}},
+		}
+	}
+
+	if viewer != nil {
+		filter["$and"] = bson.A{postAudienceFilter(*viewer)}
 	}
 
 	cur, err := collection.Find(ctx, filter, options)
@@ -87,39 +105,73 @@ func (repository *Repository) GetPosts(userID string, isHomePage bool, friendIDs
 	return posts, nil
 }
 
+// UpdatePost replaces the post when it is still at post.Version and returns
+// errors.VersionConflict when another update got there first.
 func (repository *Repository) UpdatePost(postID string, post model.Post) (*model.Post, error) {
 	collection := repository.MongoClient.Database("socium").Collection("posts")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"id": postID}
-
 	postEntity := convertPostModelToPostEntity(post)
+	postEntity.Version = post.Version + 1
+
+	err := replaceVersioned(ctx, collection, postID, post.Version, postEntity)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.PostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	cur := collection.FindOneAndReplace(ctx, filter, postEntity)
+	return repository.GetPost(postID)
+}
 
-	if cur.Err() != nil {
-		return nil, cur.Err()
+// AddPostCommentID appends the comment to the post without replacing the rest of the post.
+func (repository *Repository) AddPostCommentID(postID, commentID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$push": bson.M{"commentIds": commentID},
+		"$inc":  bson.M{"version": 1},
 	}
 
-	if cur == nil {
-		return nil, errors.PostNotFound
+	result, err := collection.UpdateOne(ctx, bson.M{"id": postID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.PostNotFound
 	}
 
-	return repository.GetPost(postID)
+	return nil
 }
 
-func (repository *Repository) GetUserPosts(userID string) ([]model.Post, error) {
+// GetHashtagPosts returns up to limit posts with the hashtag that the viewer can see, newest
+// first, starting after cursor when it is set.
+func (repository *Repository) GetHashtagPosts(viewer model.User, hashtag string, cursor *model.Cursor, limit int) ([]model.Post, error) {
 	collection := repository.MongoClient.Database("socium").Collection("posts")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	options := options.Find()
-	options.SetSort(bson.M{"createdAt": -1})
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+	options.SetLimit(int64(limit))
 
-	filter := bson.M{"userId": userID}
+	filter := bson.M{
+		"hashtags":  hashtag,
+		"deletedAt": nil,
+		"$and":      bson.A{postAudienceFilter(viewer)},
+	}
+	if cursor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$lt": cursor.ID}},
+		}
+	}
 
-	cur, err := collection.Find(ctx, filter)
+	cur, err := collection.Find(ctx, filter, options)
 	if err != nil {
 		return nil, err
 	}
@@ -133,33 +185,285 @@ func (repository *Repository) GetUserPosts(userID string) ([]model.Post, error)
 		}
 		posts = append(posts, convertPostEntityToPostModel(postEntity))
 	}
+
 	return posts, nil
 }
 
-func (repository *Repository) GetPostCount() (int, error) {
+// GetTrendingHashtags returns the limit hashtags used the most since the given time, counting
+// public posts and the comments of public posts, most used first.
+func (repository *Repository) GetTrendingHashtags(since time.Time, limit int) ([]model.TrendingHashtag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	recentlyTagged := bson.M{"createdAt": bson.M{"$gte": since}, "hashtags.0": bson.M{"$exists": true}}
+	publicPost := bson.M{"audience": model.AudiencePublic, "deletedAt": nil}
+	countStages := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$hashtags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$hashtags", "count": bson.M{"$sum": 1}}}},
+	}
+
+	postsPipeline := append(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$and": bson.A{recentlyTagged, publicPost}}}},
+	}, countStages...)
+
+	commentsPipeline := append(mongo.Pipeline{
+		{{Key: "$match", Value: recentlyTagged}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "posts",
+			"localField":   "postId",
+			"foreignField": "id",
+			"as":           "post",
+		}}},
+		{{Key: "$match", Value: bson.M{"post": bson.M{"$elemMatch": publicPost}}}},
+	}, countStages...)
+
+	counts := map[string]int{}
+	pipelines := map[string]mongo.Pipeline{"posts": postsPipeline, "comments": commentsPipeline}
+	for collectionName, pipeline := range pipelines {
+		cur, err := repository.MongoClient.Database("socium").Collection(collectionName).Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+
+		for cur.Next(ctx) {
+			hashtagCount := struct {
+				Hashtag string `bson:"_id"`
+				Count   int    `bson:"count"`
+			}{}
+			if err := cur.Decode(&hashtagCount); err != nil {
+				return nil, err
+			}
+			counts[hashtagCount.Hashtag] += hashtagCount.Count
+		}
+	}
+
+	var trendingHashtags []model.TrendingHashtag
+	for hashtag, count := range counts {
+		trendingHashtags = append(trendingHashtags, model.TrendingHashtag{Hashtag: hashtag, Count: count})
+	}
+	sort.Slice(trendingHashtags, func(i, j int) bool {
+		if trendingHashtags[i].Count != trendingHashtags[j].Count {
+			return trendingHashtags[i].Count > trendingHashtags[j].Count
+		}
+		return trendingHashtags[i].Hashtag < trendingHashtags[j].Hashtag
+	})
+	if len(trendingHashtags) > limit {
+		trendingHashtags = trendingHashtags[:limit]
+	}
+
+	return trendingHashtags, nil
+}
+
+// GetUntaggedTexts returns the IDs and texts of the documents saved before hashtags were
+// extracted.
+func (repository *Repository) GetUntaggedTexts(collectionName, textField string) (map[string]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"hashtags": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+
+	texts := map[string]string{}
+	for cur.Next(ctx) {
+		document := bson.M{}
+		if err := cur.Decode(&document); err != nil {
+			return nil, err
+		}
+
+		id, _ := document["id"].(string)
+		text, _ := document[textField].(string)
+		texts[id] = text
+	}
+
+	return texts, nil
+}
+
+// SetHashtags stores the hashtags of a document saved before hashtags were extracted.
+func (repository *Repository) SetHashtags(collectionName, id string, hashtags []string) error {
+	collection := repository.MongoClient.Database("socium").Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": id, "hashtags": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"hashtags": nonNil(hashtags)}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+func (repository *Repository) GetPostsByIDList(postIDs []string) ([]model.Post, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"id": bson.M{"$in": nonNil(postIDs)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []model.Post
+	for cur.Next(ctx) {
+		postEntity := PostEntity{}
+		err := cur.Decode(&postEntity)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, convertPostEntityToPostModel(postEntity))
+	}
+
+	return posts, nil
+}
+
+// GetUserPhotos returns up to limit media items of the posts of the user that the viewer can
+// see, newest post first and in post order within a post, starting after cursor when it is set.
+func (repository *Repository) GetUserPhotos(viewer model.User, userID string, cursor *model.PhotoCursor, limit int) ([]model.Photo, error) {
 	collection := repository.MongoClient.Database("socium").Collection("posts")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	postCount, err := collection.CountDocuments(ctx, bson.M{})
+	filter := bson.M{
+		"userId":    userID,
+		"deletedAt": nil,
+		"media.0":   bson.M{"$exists": true},
+		"$and":      bson.A{postAudienceFilter(viewer)},
+	}
+	if cursor != nil {
+		filter["createdAt"] = bson.M{"$lte": cursor.CreatedAt}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$unwind", Value: bson.M{"path": "$media", "includeArrayIndex": "position"}}},
+	}
+	if cursor != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"createdAt": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$lt": cursor.PostID}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": cursor.PostID, "position": bson.M{"$gt": cursor.Position}},
+		}}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}, {Key: "position", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	)
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var photos []model.Photo
+	for cur.Next(ctx) {
+		photoDocument := struct {
+			ID        string          `bson:"id"`
+			Media     PostMediaEntity `bson:"media"`
+			Position  int             `bson:"position"`
+			CreatedAt time.Time       `bson:"createdAt"`
+		}{}
+		if err := cur.Decode(&photoDocument); err != nil {
+			return nil, err
+		}
+
+		photos = append(photos, model.Photo{
+			PostID:    photoDocument.ID,
+			MediaID:   photoDocument.Media.MediaID,
+			Caption:   photoDocument.Media.Caption,
+			AltText:   photoDocument.Media.AltText,
+			Position:  photoDocument.Position,
+			CreatedAt: photoDocument.CreatedAt,
+		})
+	}
+
+	return photos, nil
+}
+
+// MigratePostMedia turns the image of posts stored before media lists existed into their
+// first media item and returns the number of posts updated.
+func (repository *Repository) MigratePostMedia() (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Images longer than a media ID are data that MigrateInlineImages could not move.
+	filter := bson.M{
+		"image": bson.M{"$nin": bson.A{"", nil}},
+		"media": nil,
+		"$expr": bson.M{"$lte": bson.A{bson.M{"$strLenBytes": "$image"}, 32}},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"media":   bson.A{bson.M{"mediaId": "$image", "caption": "", "altText": ""}},
+			"version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+		}}},
+	}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
 	if err != nil {
 		return 0, err
 	}
 
-	return int(postCount), nil
+	return int(result.ModifiedCount), nil
 }
 
-func (repository *Repository) DeleteUserPost(postID, userID string) error {
+func (repository *Repository) GetUserPosts(userID string) ([]model.Post, error) {
 	collection := repository.MongoClient.Database("socium").Collection("posts")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"id": postID, "userId": userID}
+	options := options.Find()
+	options.SetSort(bson.M{"createdAt": -1})
 
-	_, err := collection.DeleteOne(ctx, filter)
+	filter := bson.M{"userId": userID}
+
+	cur, err := collection.Find(ctx, filter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	var posts []model.Post
+	for cur.Next(ctx) {
+		postEntity := PostEntity{}
+		err := cur.Decode(&postEntity)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, convertPostEntityToPostModel(postEntity))
+	}
+	return posts, nil
+}
+
+func (repository *Repository) GetPostCount() (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	postCount, err := collection.CountDocuments(ctx, bson.M{"deletedAt": nil})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(postCount), nil
+}
+
+// postAudienceFilter matches the posts the viewer is allowed to see. Posts of users who
+// blocked the viewer are never matched.
+func postAudienceFilter(viewer model.User) bson.M {
+	friendIDs := viewer.FriendIDs
+	if friendIDs == nil {
+		friendIDs = []string{}
+	}
+
+	return bson.M{"userId": bson.M{"$nin": nonNil(viewer.BlockedByUserIDs)}, "$or": bson.A{
+		bson.M{"userId": viewer.ID},
+		bson.M{"audience": model.AudiencePublic},
+		bson.M{"audience": model.AudienceFriends, "userId": bson.M{"$in": friendIDs}},
+		bson.M{"audience": model.AudienceCustom, "audienceUserIds": viewer.ID},
+		// Posts stored before audiences existed only have the isPrivate flag.
+		bson.M{"audience": bson.M{"$exists": false}, "isPrivate": false},
+		bson.M{"audience": bson.M{"$exists": false}, "isPrivate": true, "userId": bson.M{"$in": friendIDs}},
+	}}
 }
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// SetReaction stores the reaction, replacing the user's previous reaction to the same target.
+func (repository *Repository) SetReaction(reaction model.Reaction) error {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"targetType": reaction.TargetType, "targetId": reaction.TargetID, "userId": reaction.UserID}
+	update := bson.M{
+		"$set":         bson.M{"type": reaction.Type, "createdAt": reaction.CreatedAt},
+		"$setOnInsert": bson.M{"id": reaction.ID, "postId": reaction.PostID},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	return err
+}
+
+// GetReaction returns nil when the user has not reacted to the target.
+func (repository *Repository) GetReaction(targetType, targetID, userID string) (*model.Reaction, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"targetType": targetType, "targetId": targetID, "userId": userID}
+
+	cur := collection.FindOne(ctx, filter)
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	reactionEntity := ReactionEntity{}
+	err := cur.Decode(&reactionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	reaction := convertReactionEntityToReactionModel(reactionEntity)
+
+	return &reaction, nil
+}
+
+func (repository *Repository) DeleteReaction(targetType, targetID, userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"targetType": targetType, "targetId": targetID, "userId": userID}
+
+	_, err := collection.DeleteOne(ctx, filter)
+
+	return err
+}
+
+// DeleteReactionOfType removes the user's reaction only when it has the given type and
+// reports whether one was removed.
+func (repository *Repository) DeleteReactionOfType(targetType, targetID, userID, reactionType string) (bool, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"targetType": targetType, "targetId": targetID, "userId": userID, "type": reactionType}
+
+	result, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
+
+// GetReactions returns up to limit reactions to the target, newest first and starting after
+// cursor when it is set. An empty reactionType returns every type.
+func (repository *Repository) GetReactions(targetType, targetID, reactionType string, cursor *model.Cursor, limit int) ([]model.Reaction, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := options.Find()
+	options.SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "id", Value: -1}})
+	options.SetLimit(int64(limit))
+
+	filter := bson.M{"targetType": targetType, "targetId": targetID}
+	if len(reactionType) > 0 {
+		filter["type"] = reactionType
+	}
+
+	if cursor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"createdAt": cursor.CreatedAt, "id": bson.M{"$lt": cursor.ID}},
+		}
+	}
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var reactions []model.Reaction
+	for cur.Next(ctx) {
+		reactionEntity := ReactionEntity{}
+		err := cur.Decode(&reactionEntity)
+		if err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, convertReactionEntityToReactionModel(reactionEntity))
+	}
+
+	return reactions, nil
+}
+
+// GetReactionCounts returns the number of reactions of each type for each of the targets.
+func (repository *Repository) GetReactionCounts(targetType string, targetIDs []string) (map[string]map[string]int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"targetType": targetType, "targetId": bson.M{"$in": nonNil(targetIDs)}}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"targetId": "$targetId", "type": "$type"},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	reactionCounts := map[string]map[string]int{}
+	for cur.Next(ctx) {
+		reactionCount := struct {
+			ID struct {
+				TargetID string `bson:"targetId"`
+				Type     string `bson:"type"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}{}
+		err := cur.Decode(&reactionCount)
+		if err != nil {
+			return nil, err
+		}
+
+		if reactionCounts[reactionCount.ID.TargetID] == nil {
+			reactionCounts[reactionCount.ID.TargetID] = map[string]int{}
+		}
+		reactionCounts[reactionCount.ID.TargetID][reactionCount.ID.Type] = reactionCount.Count
+	}
+
+	return reactionCounts, nil
+}
+
+// GetUserReactionTypes returns the type of the user's reaction to each target they reacted to.
+func (repository *Repository) GetUserReactionTypes(targetType string, targetIDs []string, userID string) (map[string]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"targetType": targetType, "targetId": bson.M{"$in": nonNil(targetIDs)}, "userId": userID}
+
+	cur, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	reactionTypes := map[string]string{}
+	for cur.Next(ctx) {
+		reactionEntity := ReactionEntity{}
+		err := cur.Decode(&reactionEntity)
+		if err != nil {
+			return nil, err
+		}
+		reactionTypes[reactionEntity.TargetID] = reactionEntity.Type
+	}
+
+	return reactionTypes, nil
+}
+
+// MigrateLegacyLikes turns the user IDs kept in the whoLikesUserIds array of posts into like
+// reactions and removes the array. It returns the number of posts migrated.
+func (repository *Repository) MigrateLegacyLikes() (int, error) {
+	posts := repository.MongoClient.Database("socium").Collection("posts")
+	reactions := repository.MongoClient.Database("socium").Collection("reactions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cur, err := posts.Find(ctx, bson.M{"whoLikesUserIds": bson.M{"$exists": true}})
+	if err != nil {
+		return 0, err
+	}
+
+	migratedPosts := 0
+	for cur.Next(ctx) {
+		legacyPost := struct {
+			ID              string    `bson:"id"`
+			WhoLikesUserIDs []string  `bson:"whoLikesUserIds"`
+			UpdatedAt       time.Time `bson:"updatedAt"`
+		}{}
+		err := cur.Decode(&legacyPost)
+		if err != nil {
+			return migratedPosts, err
+		}
+
+		for _, userID := range legacyPost.WhoLikesUserIDs {
+			filter := bson.M{"targetType": model.ReactionTargetPost, "targetId": legacyPost.ID, "userId": userID}
+			update := bson.M{"$setOnInsert": ReactionEntity{
+				ID:         userID + legacyPost.ID,
+				UserID:     userID,
+				TargetType: model.ReactionTargetPost,
+				TargetID:   legacyPost.ID,
+				PostID:     legacyPost.ID,
+				Type:       model.ReactionLike,
+				CreatedAt:  legacyPost.UpdatedAt,
+			}}
+			_, err := reactions.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+			if err != nil {
+				return migratedPosts, err
+			}
+		}
+
+		_, err = posts.UpdateOne(ctx, bson.M{"id": legacyPost.ID}, bson.M{"$unset": bson.M{"whoLikesUserIds": ""}})
+		if err != nil {
+			return migratedPosts, err
+		}
+		migratedPosts++
+	}
+
+	return migratedPosts, nil
+}
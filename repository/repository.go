@@ -5,12 +5,15 @@ import (
 	"log"
 	"time"
 
+	"github.com/anilaydinn/socium-be/errors"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Repository struct {
-	MongoClient *mongo.Client
+	MongoClient          *mongo.Client
+	supportsTransactions bool
 }
 
 func NewRepository(uri string) *Repository {
@@ -24,5 +27,275 @@ func NewRepository(uri string) *Repository {
 		log.Fatal(err)
 	}
 
-	return &Repository{client}
+	repository := &Repository{MongoClient: client}
+	repository.supportsTransactions = repository.checkTransactionSupport()
+	repository.createIndexes()
+	repository.createViews()
+
+	return repository
+}
+
+// checkTransactionSupport reports whether the server is a replica set member or mongos,
+// the deployments where multi-document transactions are available.
+func (repository *Repository) checkTransactionSupport() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hello := struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}{}
+	err := repository.MongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	if err != nil {
+		log.Println("Could not check transaction support: " + err.Error())
+		return false
+	}
+
+	return len(hello.SetName) > 0 || hello.Msg == "isdbgrid"
+}
+
+// WithTransaction runs fn in a transaction when the deployment supports them. Otherwise fn
+// runs step by step, so callers order their writes to leave data consistent when retried.
+func (repository *Repository) WithTransaction(fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !repository.supportsTransactions {
+		return fn(ctx)
+	}
+
+	session, err := repository.MongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionContext)
+	})
+
+	return err
+}
+
+// replaceVersioned replaces the document with the given id only when it is still at version.
+// It returns mongo.ErrNoDocuments when the document does not exist and errors.VersionConflict
+// when it was changed since it was read.
+func replaceVersioned(ctx context.Context, collection *mongo.Collection, id string, version int, replacement interface{}) error {
+	filter := bson.M{"id": id, "version": version}
+	if version == 0 {
+		// Documents written before versioning have no version field.
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	result, err := collection.ReplaceOne(ctx, filter, replacement)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := collection.CountDocuments(ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return errors.VersionConflict
+}
+
+// createViews makes sure the read only views used by aggregations exist and use the current
+// pipelines.
+func (repository *Repository) createViews() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	database := repository.MongoClient.Database("socium")
+
+	viewNames, err := database.ListCollectionNames(ctx, bson.M{"name": friendGraphView})
+	if err != nil {
+		log.Println("Could not list views: " + err.Error())
+		return
+	}
+
+	friendGraphPipeline := mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"_id": 0, "id": 1, userFriendIDsField: 1, "isActivated": 1, "isBanned": 1}}},
+	}
+	if len(viewNames) != 0 {
+		// Existing views keep their old pipeline unless it is replaced.
+		command := bson.D{
+			{Key: "collMod", Value: friendGraphView},
+			{Key: "viewOn", Value: "users"},
+			{Key: "pipeline", Value: friendGraphPipeline},
+		}
+		err = database.RunCommand(ctx, command).Err()
+	} else {
+		err = database.CreateView(ctx, friendGraphView, "users", friendGraphPipeline)
+	}
+	if err != nil {
+		log.Println("Could not create friend graph view: " + err.Error())
+	}
+}
+
+// createIndexes makes sure the indexes used by paginated queries exist.
+func (repository *Repository) createIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	postsIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "id", Value: -1}},
+	}
+	_, err := repository.MongoClient.Database("socium").Collection("posts").Indexes().CreateOne(ctx, postsIndex)
+	if err != nil {
+		log.Println("Could not create posts index: " + err.Error())
+	}
+
+	commentsIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "postId", Value: 1}, {Key: "parentCommentId", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "id", Value: 1}},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("comments").Indexes().CreateOne(ctx, commentsIndex)
+	if err != nil {
+		log.Println("Could not create comments index: " + err.Error())
+	}
+
+	// The unique index keeps a single reaction per user and target when requests race.
+	reactionsIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "id", Value: -1}},
+		},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("reactions").Indexes().CreateMany(ctx, reactionsIndexes)
+	if err != nil {
+		log.Println("Could not create reactions indexes: " + err.Error())
+	}
+
+	hashtagsIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "hashtags", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "id", Value: -1}},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("posts").Indexes().CreateOne(ctx, hashtagsIndex)
+	if err != nil {
+		log.Println("Could not create posts hashtags index: " + err.Error())
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("comments").Indexes().CreateOne(ctx, hashtagsIndex)
+	if err != nil {
+		log.Println("Could not create comments hashtags index: " + err.Error())
+	}
+
+	// Users created before usernames existed have none until the maintenance run assigns one,
+	// so only set usernames have to be unique.
+	usernameIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"username": bson.M{"$gt": ""}}),
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("users").Indexes().CreateOne(ctx, usernameIndex)
+	if err != nil {
+		log.Println("Could not create users username index: " + err.Error())
+	}
+
+	// The friendship graph queries look users up by id at every hop and by friend.
+	usersGraphIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: userFriendIDsField, Value: 1}},
+		},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("users").Indexes().CreateMany(ctx, usersGraphIndexes)
+	if err != nil {
+		log.Println("Could not create users graph indexes: " + err.Error())
+	}
+
+	// Nearby users are found through the location, which only users who shared one have.
+	locationIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("users").Indexes().CreateOne(ctx, locationIndex)
+	if err != nil {
+		log.Println("Could not create users location index: " + err.Error())
+	}
+
+	// The unique pair index keeps a single friendship per pair of users when requests race.
+	friendshipsIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "pairId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "addresseeId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "requesterId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("friendships").Indexes().CreateMany(ctx, friendshipsIndexes)
+	if err != nil {
+		log.Println("Could not create friendships indexes: " + err.Error())
+	}
+
+	// The unique index keeps a single follow per follower and followee when follows race.
+	followsIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "followerId", Value: 1}, {Key: "followeeId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "followerId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "followeeId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "id", Value: -1}},
+		},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("follows").Indexes().CreateMany(ctx, followsIndexes)
+	if err != nil {
+		log.Println("Could not create follows indexes: " + err.Error())
+	}
+
+	suggestionsIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("suggestions").Indexes().CreateOne(ctx, suggestionsIndex)
+	if err != nil {
+		log.Println("Could not create suggestions index: " + err.Error())
+	}
+
+	// The unique key lets concurrent failures upsert the same attempt instead of creating copies.
+	loginAttemptsIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("loginAttempts").Indexes().CreateOne(ctx, loginAttemptsIndex)
+	if err != nil {
+		log.Println("Could not create login attempts index: " + err.Error())
+	}
+
+	// Revocations are only needed until the access token expires on its own.
+	revokedTokensIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "tokenId", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("revokedTokens").Indexes().CreateMany(ctx, revokedTokensIndexes)
+	if err != nil {
+		log.Println("Could not create revoked tokens indexes: " + err.Error())
+	}
+
+	notificationsIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "id", Value: -1}},
+	}
+	_, err = repository.MongoClient.Database("socium").Collection("notifications").Indexes().CreateOne(ctx, notificationsIndex)
+	if err != nil {
+		log.Println("Could not create notifications index: " + err.Error())
+	}
 }
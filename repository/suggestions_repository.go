@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// GetSuggestions returns the precomputed friend suggestions of the user, best first, and nil
+// when none were computed yet.
+func (repository *Repository) GetSuggestions(userID string) ([]model.Suggestion, error) {
+	collection := repository.MongoClient.Database("socium").Collection("suggestions")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur := collection.FindOne(ctx, bson.M{"userId": userID})
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if cur.Err() != nil {
+		return nil, cur.Err()
+	}
+
+	userSuggestionsEntity := UserSuggestionsEntity{}
+	err := cur.Decode(&userSuggestionsEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []model.Suggestion{}
+	for _, suggestionEntity := range userSuggestionsEntity.Suggestions {
+		suggestions = append(suggestions, convertSuggestionEntityToSuggestionModel(suggestionEntity))
+	}
+
+	return suggestions, nil
+}
+
+// ReplaceSuggestions stores the friend suggestions of every user by user ID and removes those
+// of users that are not in suggestionsByUserID anymore.
+func (repository *Repository) ReplaceSuggestions(suggestionsByUserID map[string][]model.Suggestion, computedAt time.Time) error {
+	collection := repository.MongoClient.Database("socium").Collection("suggestions")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var userIDs []string
+	var writes []mongo.WriteModel
+	for userID, suggestions := range suggestionsByUserID {
+		userSuggestionsEntity := UserSuggestionsEntity{
+			UserID:      userID,
+			Suggestions: []SuggestionEntity{},
+			ComputedAt:  computedAt,
+		}
+		for _, suggestion := range suggestions {
+			userSuggestionsEntity.Suggestions = append(userSuggestionsEntity.Suggestions, convertSuggestionModelToSuggestionEntity(suggestion))
+		}
+
+		userIDs = append(userIDs, userID)
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"userId": userID}).
+			SetReplacement(userSuggestionsEntity).
+			SetUpsert(true))
+	}
+
+	if len(writes) > 0 {
+		if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+
+	_, err := collection.DeleteMany(ctx, bson.M{"userId": bson.M{"$nin": nonNil(userIDs)}})
+
+	return err
+}
+
+// GetSuggestionUsers returns the users who can log in with only the fields suggestions are
+// computed from: their ID, friends and coordinates.
+func (repository *Repository) GetSuggestionUsers() ([]model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	filter := bson.M{"isActivated": true, "isBanned": bson.M{"$ne": true}}
+	options := options.Find().SetProjection(bson.M{"_id": 0, "id": 1, userFriendIDsField: 1, "latitude": 1, "longitude": 1})
+
+	cur, err := collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []model.User
+	for cur.Next(ctx) {
+		userEntity := UserEntity{}
+		if err := cur.Decode(&userEntity); err != nil {
+			return nil, err
+		}
+		users = append(users, convertUserEntityToUserModel(userEntity))
+	}
+
+	return users, nil
+}
+
+// GetNearbyUserDistances returns up to limit users who can log in and live within radiusKM of
+// the coordinates, leaving out the user, with their distance in kilometers by user ID.
+func (repository *Repository) GetNearbyUserDistances(userID string, latitude, longitude, radiusKM float64, limit int) (map[string]float64, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		nearbyUsersStage(latitude, longitude, radiusKM, []string{userID}),
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "id": 1, "distance": 1}}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	distances := map[string]float64{}
+	for cur.Next(ctx) {
+		result := struct {
+			ID       string  `bson:"id"`
+			Distance float64 `bson:"distance"`
+		}{}
+		if err := cur.Decode(&result); err != nil {
+			return nil, err
+		}
+		distances[result.ID] = result.Distance / 1000
+	}
+
+	return distances, nil
+}
+
+// GetNearbyUsers returns up to limit users who can log in and live within radiusKM of the
+// coordinates, closest first, leaving out excludedUserIDs.
+func (repository *Repository) GetNearbyUsers(latitude, longitude, radiusKM float64, limit int, excludedUserIDs []string) ([]model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		nearbyUsersStage(latitude, longitude, radiusKM, excludedUserIDs),
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []model.User{}
+	for cur.Next(ctx) {
+		userEntity := UserEntity{}
+		if err := cur.Decode(&userEntity); err != nil {
+			return nil, err
+		}
+		users = append(users, convertUserEntityToUserModel(userEntity))
+	}
+
+	return users, nil
+}
+
+// nearbyUsersStage finds the users who can log in and live within radiusKM of the coordinates,
+// closest first, with their distance in meters.
+func nearbyUsersStage(latitude, longitude, radiusKM float64, excludedUserIDs []string) bson.D {
+	return bson.D{{Key: "$geoNear", Value: bson.M{
+		"near":          GeoPointEntity{Type: "Point", Coordinates: []float64{longitude, latitude}},
+		"distanceField": "distance",
+		"maxDistance":   radiusKM * 1000,
+		"spherical":     true,
+		"query": bson.M{
+			"id":          bson.M{"$nin": nonNil(excludedUserIDs)},
+			"isActivated": true,
+			"isBanned":    bson.M{"$ne": true},
+		},
+	}}}
+}
+
+// GetRecentInteractionCounts returns how many reactions and comments each pair of users left
+// on each other's posts since the given time, by user ID and then by the other user's ID.
+func (repository *Repository) GetRecentInteractionCounts(since time.Time) (map[string]map[string]int, error) {
+	interactionCounts := map[string]map[string]int{}
+	for _, collectionName := range []string{"reactions", "comments"} {
+		if err := repository.countInteractions(collectionName, since, interactionCounts); err != nil {
+			return nil, err
+		}
+	}
+
+	return interactionCounts, nil
+}
+
+// countInteractions adds the reactions or comments users left on posts of other users since
+// the given time to interactionCounts, in both directions.
+func (repository *Repository) countInteractions(collectionName string, since time.Time, interactionCounts map[string]map[string]int) error {
+	collection := repository.MongoClient.Database("socium").Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"createdAt": bson.M{"$gte": since}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "posts",
+			"let":  bson.M{"postId": "$postId"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$id", "$$postId"}}, "deletedAt": nil}},
+				bson.M{"$project": bson.M{"_id": 0, "userId": 1}},
+			},
+			"as": "post",
+		}}},
+		{{Key: "$unwind", Value: "$post"}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$ne": bson.A{"$userId", "$post.userId"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"userId": "$userId", "ownerId": "$post.userId"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+
+	for cur.Next(ctx) {
+		result := struct {
+			ID struct {
+				UserID  string `bson:"userId"`
+				OwnerID string `bson:"ownerId"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}{}
+		if err := cur.Decode(&result); err != nil {
+			return err
+		}
+
+		addInteractionCount(interactionCounts, result.ID.UserID, result.ID.OwnerID, result.Count)
+		addInteractionCount(interactionCounts, result.ID.OwnerID, result.ID.UserID, result.Count)
+	}
+
+	return nil
+}
+
+func addInteractionCount(interactionCounts map[string]map[string]int, userID, otherUserID string, count int) {
+	if interactionCounts[userID] == nil {
+		interactionCounts[userID] = map[string]int{}
+	}
+	interactionCounts[userID][otherUserID] += count
+}
+
+// GetRecentHashtagsByUser returns the hashtags of the public posts each user created since
+// the given time, by user ID.
+func (repository *Repository) GetRecentHashtagsByUser(since time.Time) (map[string][]string, error) {
+	collection := repository.MongoClient.Database("socium").Collection("posts")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"deletedAt":  nil,
+			"audience":   model.AudiencePublic,
+			"createdAt":  bson.M{"$gte": since},
+			"hashtags.0": bson.M{"$exists": true},
+		}}},
+		{{Key: "$unwind", Value: "$hashtags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$userId", "hashtags": bson.M{"$addToSet": "$hashtags"}}}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	hashtagsByUserID := map[string][]string{}
+	for cur.Next(ctx) {
+		result := struct {
+			UserID   string   `bson:"_id"`
+			Hashtags []string `bson:"hashtags"`
+		}{}
+		if err := cur.Decode(&result); err != nil {
+			return nil, err
+		}
+		hashtagsByUserID[result.UserID] = result.Hashtags
+	}
+
+	return hashtagsByUserID, nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+func (repository *Repository) CreateRefreshToken(refreshToken model.RefreshToken) (*model.RefreshToken, error) {
+	collection := repository.MongoClient.Database("socium").Collection("refreshTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	refreshTokenEntity := convertRefreshTokenModelToRefreshTokenEntity(refreshToken)
+
+	_, err := collection.InsertOne(ctx, refreshTokenEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetRefreshTokenByHash(refreshTokenEntity.TokenHash)
+}
+
+func (repository *Repository) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	collection := repository.MongoClient.Database("socium").Collection("refreshTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"tokenHash": tokenHash}
+
+	cur := collection.FindOne(ctx, filter)
+
+	if cur.Err() != nil {
+		return nil, errors.InvalidRefreshToken
+	}
+
+	refreshTokenEntity := RefreshTokenEntity{}
+	err := cur.Decode(&refreshTokenEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := convertRefreshTokenEntityToRefreshTokenModel(refreshTokenEntity)
+
+	return &refreshToken, nil
+}
+
+func (repository *Repository) RevokeRefreshToken(refreshTokenID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("refreshTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": refreshTokenID, "isRevoked": false}
+	update := bson.M{"$set": bson.M{"isRevoked": true}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.InvalidRefreshToken
+	}
+
+	return nil
+}
+
+func (repository *Repository) RevokeUserRefreshTokens(userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("refreshTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": userID, "isRevoked": false}
+	update := bson.M{"$set": bson.M{"isRevoked": true}}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeToken stores the revocation of a single access token until it expires.
+func (repository *Repository) RevokeToken(revokedToken model.RevokedToken) error {
+	collection := repository.MongoClient.Database("socium").Collection("revokedTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	revokedTokenEntity := convertRevokedTokenModelToRevokedTokenEntity(revokedToken)
+
+	_, err := collection.InsertOne(ctx, revokedTokenEntity)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *Repository) IsTokenRevoked(tokenID string) (bool, error) {
+	collection := repository.MongoClient.Database("socium").Collection("revokedTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"tokenId": tokenID}
+
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IncrementTokenVersion invalidates every access token issued to the user so far. The user
+// version is bumped too, so replacements read before fail.
+func (repository *Repository) IncrementTokenVersion(userID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$inc": bson.M{"tokenVersion": 1, "version": 1}}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"id": userID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.UserNotFound
+	}
+
+	return nil
+}
+
+func (repository *Repository) CreateVerificationToken(verificationToken model.VerificationToken) (*model.VerificationToken, error) {
+	collection := repository.MongoClient.Database("socium").Collection("verificationTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	verificationTokenEntity := convertVerificationTokenModelToVerificationTokenEntity(verificationToken)
+
+	_, err := collection.InsertOne(ctx, verificationTokenEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetVerificationTokenByHash(verificationTokenEntity.TokenHash, verificationTokenEntity.Purpose)
+}
+
+func (repository *Repository) GetVerificationTokenByHash(tokenHash, purpose string) (*model.VerificationToken, error) {
+	collection := repository.MongoClient.Database("socium").Collection("verificationTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"tokenHash": tokenHash, "purpose": purpose}
+
+	cur := collection.FindOne(ctx, filter)
+
+	if cur.Err() != nil {
+		return nil, errors.InvalidVerificationToken
+	}
+
+	verificationTokenEntity := VerificationTokenEntity{}
+	err := cur.Decode(&verificationTokenEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	verificationToken := convertVerificationTokenEntityToVerificationTokenModel(verificationTokenEntity)
+
+	return &verificationToken, nil
+}
+
+// UseVerificationToken marks the token as used, failing when it was already used so a
+// link cannot be redeemed twice by concurrent requests.
+func (repository *Repository) UseVerificationToken(verificationTokenID string) error {
+	collection := repository.MongoClient.Database("socium").Collection("verificationTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": verificationTokenID, "isUsed": false}
+	update := bson.M{"$set": bson.M{"isUsed": true}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.InvalidVerificationToken
+	}
+
+	return nil
+}
+
+func (repository *Repository) InvalidateUserVerificationTokens(userID, purpose string) error {
+	collection := repository.MongoClient.Database("socium").Collection("verificationTokens")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": userID, "purpose": purpose, "isUsed": false}
+	update := bson.M{"$set": bson.M{"isUsed": true}}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
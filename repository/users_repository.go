@@ -6,6 +6,7 @@ import (
 	"github.com/anilaydinn/socium-be/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
@@ -16,8 +17,13 @@ func (repository *Repository) RegisterUser(user model.User) (*model.User, error)
 	defer cancel()
 
 	userEntity := convertUserModelToUserEntity(user)
+	// Versions start at 1, so a client sending version 0 means it did not read one.
+	userEntity.Version = 1
 
 	_, err := collection.InsertOne(ctx, userEntity)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errors.UsernameTaken
+	}
 
 	if err != nil {
 		return nil, err
@@ -84,27 +90,169 @@ func (repository *Repository) GetUserByEmail(email string) (*model.User, error)
 	return &user, nil
 }
 
-func (repository *Repository) UpdateUser(userID string, user model.User) (*model.User, error) {
+func (repository *Repository) GetUserByExternalIdentity(provider, subject string) (*model.User, error) {
 	collection := repository.MongoClient.Database("socium").Collection("users")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"id": userID}
+	filter := bson.M{"externalIdentities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}
+
+	cur := collection.FindOne(ctx, filter)
+
+	if cur.Err() != nil {
+		return nil, errors.UserNotFound
+	}
+
+	userEntity := UserEntity{}
+	err := cur.Decode(&userEntity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := convertUserEntityToUserModel(userEntity)
+
+	return &user, nil
+}
+
+// UpdateUser replaces the user when it is still at user.Version and returns
+// errors.VersionConflict when another update got there first.
+func (repository *Repository) UpdateUser(userID string, user model.User) (*model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
 	userEntity := convertUserModelToUserEntity(user)
+	userEntity.Version = user.Version + 1
 
-	cur := collection.FindOneAndReplace(ctx, filter, userEntity)
+	err := replaceVersioned(ctx, collection, userID, user.Version, userEntity)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.UserNotFound
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errors.UsernameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	return repository.GetUser(userID)
+}
+
+func (repository *Repository) GetUserByUsername(username string) (*model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur := collection.FindOne(ctx, bson.M{"username": username})
+
+	if cur.Err() == mongo.ErrNoDocuments {
+		return nil, errors.UserNotFound
+	}
 	if cur.Err() != nil {
 		return nil, cur.Err()
 	}
 
-	if cur == nil {
-		return nil, errors.UserNotFound
+	userEntity := UserEntity{}
+	err := cur.Decode(&userEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	user := convertUserEntityToUserModel(userEntity)
+
+	return &user, nil
+}
+
+func (repository *Repository) GetUsersByUsernames(usernames []string) ([]model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"username": bson.M{"$in": nonNil(usernames)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var users []model.User
+	for cur.Next(ctx) {
+		userEntity := UserEntity{}
+		err := cur.Decode(&userEntity)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, convertUserEntityToUserModel(userEntity))
+	}
+
+	return users, nil
+}
+
+// GetUsersWithoutUsername returns the users registered before usernames existed.
+func (repository *Repository) GetUsersWithoutUsername() ([]model.User, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cur, err := collection.Find(ctx, bson.M{"username": bson.M{"$in": bson.A{"", nil}}})
+	if err != nil {
+		return nil, err
+	}
+
+	var users []model.User
+	for cur.Next(ctx) {
+		userEntity := UserEntity{}
+		err := cur.Decode(&userEntity)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, convertUserEntityToUserModel(userEntity))
+	}
+
+	return users, nil
+}
+
+// SetUsername gives the user a username unless it already has one. It returns
+// errors.UsernameTaken when another user has the username.
+func (repository *Repository) SetUsername(userID, username string) error {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": userID, "username": bson.M{"$in": bson.A{"", nil}}}
+	update := bson.M{
+		"$set": bson.M{"username": username},
+		"$inc": bson.M{"version": 1},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.UsernameTaken
+	}
+
+	return err
+}
+
+// MuteUser adds mutedUserID to the users whose posts are left out of the user's home feed.
+func (repository *Repository) MuteUser(userID, mutedUserID string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repository.updateUserArray(ctx, userID, "$addToSet", "mutedUserIds", mutedUserID); err != nil {
+		return nil, err
 	}
 
 	return repository.GetUser(userID)
+}
+
+func (repository *Repository) UnmuteUser(userID, mutedUserID string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	if err := repository.updateUserArray(ctx, userID, "$pull", "mutedUserIds", mutedUserID); err != nil {
+		return nil, err
+	}
+
+	return repository.GetUser(userID)
 }
 
 func (repository *Repository) GetUsersByIDList(userIDs []string) ([]model.User, error) {
@@ -236,3 +384,31 @@ func (repository *Repository) GetActivatedUserCount() (int, error) {
 
 	return int(activatedUserCount), nil
 }
+
+// MigrateUserLocations gives users saved before the location existed the point their
+// coordinates describe, skipping users without valid coordinates.
+func (repository *Repository) MigrateUserLocations() (int, error) {
+	collection := repository.MongoClient.Database("socium").Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	filter := bson.M{
+		"location":  bson.M{"$exists": false},
+		"latitude":  bson.M{"$gte": -90, "$lte": 90},
+		"longitude": bson.M{"$gte": -180, "$lte": 180},
+		"$or":       bson.A{bson.M{"latitude": bson.M{"$ne": 0}}, bson.M{"longitude": bson.M{"$ne": 0}}},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"location": bson.M{"type": "Point", "coordinates": bson.A{"$longitude", "$latitude"}},
+			"version":  bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+		}}},
+	}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.ModifiedCount), nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+)
+
+const maxAlbumNameLength = 100
+
+// GetUserPhotos returns the media items of the posts of the user that the viewer can see.
+func (service *Service) GetUserPhotos(authUser model.User, userID string, getPhotosQuery model.GetPhotosQuery) (*model.PhotosCursorResponse, error) {
+	photosCursor, err := decodePhotoCursor(getPhotosQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getPhotosQuery.Limit)
+
+	// One extra photo tells whether there is a next page.
+	photos, err := service.repository.GetUserPhotos(authUser, userID, photosCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.PhotosCursorResponse{Photos: []model.Photo{}}
+	if len(photos) > limit {
+		photos = photos[:limit]
+		lastPhoto := photos[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastPhoto.CreatedAt, lastPhoto.PostID+":"+strconv.Itoa(lastPhoto.Position))
+	}
+
+	if err := service.hydratePhotos(photos); err != nil {
+		return nil, err
+	}
+	if photos != nil {
+		response.Photos = photos
+	}
+
+	return &response, nil
+}
+
+// decodePhotoCursor reads the post and the position in the post that a photo cursor holds
+// in place of an ID.
+func decodePhotoCursor(cursor string) (*model.PhotoCursor, error) {
+	postsCursor, err := decodeCursor(cursor)
+	if err != nil || postsCursor == nil {
+		return nil, err
+	}
+
+	separator := strings.LastIndex(postsCursor.ID, ":")
+	if separator < 0 {
+		return nil, errors.InvalidCursor
+	}
+	position, err := strconv.Atoi(postsCursor.ID[separator+1:])
+	if err != nil {
+		return nil, errors.InvalidCursor
+	}
+
+	return &model.PhotoCursor{
+		CreatedAt: postsCursor.CreatedAt,
+		PostID:    postsCursor.ID[:separator],
+		Position:  position,
+	}, nil
+}
+
+func (service *Service) CreateAlbum(authUser model.User, albumDTO model.AlbumDTO) (*model.Album, error) {
+	name, err := resolveAlbumName(albumDTO.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	album := model.Album{
+		ID:          utils.GenerateUUID(8),
+		UserID:      authUser.ID,
+		Name:        name,
+		Description: albumDTO.Description,
+		CreatedAt:   time.Now().UTC().Round(time.Second),
+		UpdatedAt:   time.Now().UTC().Round(time.Second),
+	}
+
+	createdAlbum, err := service.repository.CreateAlbum(album)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.getAlbumView(authUser, *createdAlbum)
+}
+
+// GetUserAlbums returns the albums of the user with the photos the viewer can see counted.
+// Albums of other users with no photo the viewer can see are left out.
+func (service *Service) GetUserAlbums(authUser model.User, userID string) ([]model.Album, error) {
+	albums, err := service.repository.GetUserAlbums(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visiblePhotos, err := service.getVisibleAlbumPhotos(authUser, albums)
+	if err != nil {
+		return nil, err
+	}
+
+	albumViews := []model.Album{}
+	for i, album := range albums {
+		if len(visiblePhotos[i]) == 0 && album.UserID != authUser.ID {
+			continue
+		}
+
+		album.PhotoCount = len(visiblePhotos[i])
+		if album.PhotoCount > 0 {
+			album.CoverPhoto = &visiblePhotos[i][0]
+		}
+		albumViews = append(albumViews, album)
+	}
+
+	return albumViews, nil
+}
+
+// GetAlbum returns the album with the photos the viewer can see.
+func (service *Service) GetAlbum(authUser model.User, albumID string) (*model.Album, error) {
+	album, err := service.repository.GetAlbum(albumID)
+	if err != nil {
+		return nil, errors.AlbumNotFound
+	}
+
+	albumView, err := service.getAlbumView(authUser, *album)
+	if err != nil {
+		return nil, err
+	}
+	if albumView.PhotoCount == 0 && album.UserID != authUser.ID {
+		return nil, errors.AlbumNotFound
+	}
+
+	return albumView, nil
+}
+
+func (service *Service) UpdateAlbum(authUser model.User, albumID string, albumDTO model.AlbumDTO) (*model.Album, error) {
+	album, err := service.getOwnedAlbum(authUser, albumID)
+	if err != nil {
+		return nil, err
+	}
+	if albumDTO.Version != 0 && albumDTO.Version != album.Version {
+		return nil, errors.VersionConflict
+	}
+
+	album.Name, err = resolveAlbumName(albumDTO.Name)
+	if err != nil {
+		return nil, err
+	}
+	album.Description = albumDTO.Description
+	album.UpdatedAt = time.Now().UTC().Round(time.Second)
+
+	updatedAlbum, err := service.repository.UpdateAlbum(albumID, *album)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.getAlbumView(authUser, *updatedAlbum)
+}
+
+func (service *Service) DeleteAlbum(authUser model.User, albumID string) error {
+	if _, err := service.getOwnedAlbum(authUser, albumID); err != nil {
+		return err
+	}
+
+	return service.repository.DeleteAlbum(albumID)
+}
+
+// AddAlbumItem adds a media item of one of the posts of the owner to the album.
+func (service *Service) AddAlbumItem(authUser model.User, albumID string, albumItemDTO model.AlbumItemDTO) (*model.Album, error) {
+	if _, err := service.getOwnedAlbum(authUser, albumID); err != nil {
+		return nil, err
+	}
+
+	post, err := service.repository.GetPost(albumItemDTO.PostID)
+	if err != nil || post.DeletedAt != nil || post.UserID != authUser.ID || !postHasMedia(*post, albumItemDTO.MediaID) {
+		return nil, errors.InvalidMedia
+	}
+
+	albumItem := model.AlbumItem{
+		PostID:  albumItemDTO.PostID,
+		MediaID: albumItemDTO.MediaID,
+		AddedAt: time.Now().UTC().Round(time.Second),
+	}
+	if err := service.repository.AddAlbumItem(albumID, albumItem); err != nil {
+		return nil, err
+	}
+
+	return service.GetAlbum(authUser, albumID)
+}
+
+func (service *Service) RemoveAlbumItem(authUser model.User, albumID, postID, mediaID string) (*model.Album, error) {
+	if _, err := service.getOwnedAlbum(authUser, albumID); err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.RemoveAlbumItem(albumID, postID, mediaID); err != nil {
+		return nil, err
+	}
+
+	return service.GetAlbum(authUser, albumID)
+}
+
+func (service *Service) getOwnedAlbum(authUser model.User, albumID string) (*model.Album, error) {
+	album, err := service.repository.GetAlbum(albumID)
+	if err != nil {
+		return nil, errors.AlbumNotFound
+	}
+	if err := checkOwnership(authUser, album.UserID); err != nil {
+		return nil, err
+	}
+
+	return album, nil
+}
+
+func resolveAlbumName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || len(name) > maxAlbumNameLength {
+		return "", errors.InvalidAlbum
+	}
+
+	return name, nil
+}
+
+// getAlbumView fills in the photos of the album that the viewer can see.
+func (service *Service) getAlbumView(viewer model.User, album model.Album) (*model.Album, error) {
+	visiblePhotos, err := service.getVisibleAlbumPhotos(viewer, []model.Album{album})
+	if err != nil {
+		return nil, err
+	}
+
+	album.Photos = visiblePhotos[0]
+	album.PhotoCount = len(album.Photos)
+	if album.PhotoCount > 0 {
+		album.CoverPhoto = &album.Photos[0]
+	}
+
+	return &album, nil
+}
+
+// getVisibleAlbumPhotos returns, for each album, the photos of its items whose post is not
+// deleted, still holds the media and can be seen by the viewer.
+func (service *Service) getVisibleAlbumPhotos(viewer model.User, albums []model.Album) ([][]model.Photo, error) {
+	var postIDs, mediaIDs []string
+	for _, album := range albums {
+		for _, item := range album.Items {
+			postIDs = append(postIDs, item.PostID)
+			mediaIDs = append(mediaIDs, item.MediaID)
+		}
+	}
+
+	posts, err := service.repository.GetPostsByIDList(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	mediaByID, err := service.getMediaByID(mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	visiblePosts := map[string]model.Post{}
+	for _, post := range posts {
+		if post.DeletedAt == nil && canViewPost(viewer, post) {
+			visiblePosts[post.ID] = post
+		}
+	}
+
+	visiblePhotos := make([][]model.Photo, len(albums))
+	for i, album := range albums {
+		for _, item := range album.Items {
+			post, ok := visiblePosts[item.PostID]
+			if !ok {
+				continue
+			}
+
+			for position, postMedia := range post.Media {
+				if postMedia.MediaID != item.MediaID {
+					continue
+				}
+				visiblePhotos[i] = append(visiblePhotos[i], model.Photo{
+					PostID:    post.ID,
+					MediaID:   postMedia.MediaID,
+					Caption:   postMedia.Caption,
+					AltText:   postMedia.AltText,
+					Media:     mediaByID[postMedia.MediaID],
+					Position:  position,
+					CreatedAt: post.CreatedAt,
+				})
+			}
+		}
+	}
+
+	return visiblePhotos, nil
+}
+
+func (service *Service) hydratePhotos(photos []model.Photo) error {
+	var mediaIDs []string
+	for _, photo := range photos {
+		mediaIDs = append(mediaIDs, photo.MediaID)
+	}
+
+	mediaByID, err := service.getMediaByID(mediaIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, photo := range photos {
+		photos[i].Media = mediaByID[photo.MediaID]
+	}
+
+	return nil
+}
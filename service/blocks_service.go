@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
+)
+
+// BlockUser blocks the target user for the user. The blocked user no longer sees the
+// blocker's profile, posts and comments, cannot send them friend requests or follow them
+// and is no longer their friend or follower.
+func (service *Service) BlockUser(authUser model.User, userID, targetID string) error {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return err
+	}
+	if targetID == userID {
+		return errors.InvalidBlock
+	}
+	if _, err := service.getVisibleUser(authUser, targetID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Round(time.Second)
+	return service.repository.WithTransaction(func(ctx context.Context) error {
+		err := service.repository.BlockUser(ctx, model.Friendship{
+			ID:          utils.GenerateUUID(8),
+			RequesterID: userID,
+			AddresseeID: targetID,
+			Status:      model.FriendshipBlocked,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		if err := service.repository.RemoveFriendIDs(ctx, userID, targetID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteFollowsBetween(ctx, userID, targetID); err != nil {
+			return err
+		}
+
+		return service.repository.AddBlockedByUserID(ctx, targetID, userID)
+	})
+}
+
+func (service *Service) UnblockUser(authUser model.User, userID, targetID string) error {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return err
+	}
+
+	return service.repository.WithTransaction(func(ctx context.Context) error {
+		if err := service.repository.UnblockUser(ctx, userID, targetID); err != nil {
+			return err
+		}
+
+		err := service.repository.RemoveBlockedByUserID(ctx, targetID, userID)
+		if err == errors.UserNotFound {
+			// The blocked user was deleted since, so nothing references the block anymore.
+			return nil
+		}
+		return err
+	})
+}
+
+// GetBlockedUsers returns the users the user blocked, most recently blocked first.
+func (service *Service) GetBlockedUsers(authUser model.User, userID string) ([]model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	blocks, err := service.repository.GetBlocks(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockedIDs []string
+	for _, block := range blocks {
+		blockedIDs = append(blockedIDs, block.AddresseeID)
+	}
+
+	return service.getUsersInOrder(blockedIDs)
+}
+
+// MuteUser leaves the posts of the target user out of the user's home feed. Unlike a block
+// it changes nothing for the target user.
+func (service *Service) MuteUser(authUser model.User, userID, targetID string) (*model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+	if targetID == userID {
+		return nil, errors.InvalidBlock
+	}
+	if _, err := service.getVisibleUser(authUser, targetID); err != nil {
+		return nil, err
+	}
+
+	return service.repository.MuteUser(userID, targetID)
+}
+
+func (service *Service) UnmuteUser(authUser model.User, userID, targetID string) (*model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	return service.repository.UnmuteUser(userID, targetID)
+}
+
+func (service *Service) GetMutedUsers(authUser model.User, userID string) ([]model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	return service.getUsersInOrder(authUser.MutedUserIDs)
+}
+
+// getVisibleUser returns the user unless they blocked the viewer, who must not find them.
+func (service *Service) getVisibleUser(viewer model.User, userID string) (*model.User, error) {
+	if utils.Contains(viewer.BlockedByUserIDs, userID) {
+		return nil, errors.UserNotFound
+	}
+
+	user, err := service.repository.GetUser(userID)
+	if err != nil {
+		return nil, errors.UserNotFound
+	}
+
+	return user, nil
+}
+
+// getBlockedUserIDs returns the users blocked by the viewer and the users who blocked the
+// viewer, which user searches leave out.
+func (service *Service) getBlockedUserIDs(viewer model.User) ([]string, error) {
+	blocks, err := service.repository.GetBlocks(viewer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedUserIDs := append([]string{}, viewer.BlockedByUserIDs...)
+	for _, block := range blocks {
+		blockedUserIDs = append(blockedUserIDs, block.AddresseeID)
+	}
+
+	return blockedUserIDs, nil
+}
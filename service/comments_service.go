@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
+)
+
+// GetPostComments returns a page of the top level comments of the post, or of the replies
+// to parentCommentID when it is set.
+func (service *Service) GetPostComments(authUser model.User, postID string, getCommentsQuery model.GetCommentsQuery) (*model.CommentsCursorResponse, error) {
+	if _, err := service.getVisiblePost(authUser, postID); err != nil {
+		return nil, err
+	}
+
+	if len(getCommentsQuery.ParentCommentID) != 0 {
+		if _, err := service.getPostComment(postID, getCommentsQuery.ParentCommentID); err != nil {
+			return nil, err
+		}
+	}
+
+	commentsCursor, err := decodeCursor(getCommentsQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getCommentsQuery.Limit)
+
+	// One extra comment tells whether there is a next page. Users who blocked the viewer
+	// are hidden from them.
+	comments, err := service.repository.GetPostComments(postID, getCommentsQuery.ParentCommentID, authUser.BlockedByUserIDs, commentsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.CommentsCursorResponse{Comments: []model.Comment{}}
+	if len(comments) > limit {
+		comments = comments[:limit]
+		lastComment := comments[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastComment.CreatedAt, lastComment.ID)
+	}
+
+	if err := service.hydrateComments(authUser, comments); err != nil {
+		return nil, err
+	}
+	if comments != nil {
+		response.Comments = comments
+	}
+
+	return &response, nil
+}
+
+func (service *Service) UpdateComment(authUser model.User, postID, commentID string, commentDTO model.CommentDTO) (*model.Comment, error) {
+	post, err := service.getVisiblePost(authUser, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := service.getPostComment(postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwnership(authUser, comment.UserID); err != nil {
+		return nil, err
+	}
+
+	mentionedUsers, err := service.resolveMentions(commentDTO.Content)
+	if err != nil {
+		return nil, err
+	}
+	previouslyMentionedIDs := comment.MentionedUserIDs
+
+	comment.Content = commentDTO.Content
+	comment.Hashtags = utils.ExtractHashtags(commentDTO.Content)
+	comment.MentionedUserIDs = getUserIDs(mentionedUsers)
+	comment.IsEdited = true
+	comment.UpdatedAt = time.Now().UTC().Round(time.Second)
+
+	updatedComment, err := service.repository.UpdateComment(commentID, *comment)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyMentions(authUser, *post, commentID, mentionedUsers, previouslyMentionedIDs)
+
+	comments := []model.Comment{*updatedComment}
+	if err := service.hydrateComments(authUser, comments); err != nil {
+		return nil, err
+	}
+
+	return &comments[0], nil
+}
+
+// DeleteComment lets the comment author or the post owner delete a comment with its replies.
+func (service *Service) DeleteComment(authUser model.User, postID, commentID string) error {
+	post, err := service.repository.GetPost(postID)
+	if err != nil || post.DeletedAt != nil {
+		return errors.PostNotFound
+	}
+
+	comment, err := service.getPostComment(postID, commentID)
+	if err != nil {
+		return err
+	}
+	if authUser.ID != comment.UserID && authUser.ID != post.UserID {
+		return errors.Forbidden
+	}
+
+	return service.repository.WithTransaction(func(ctx context.Context) error {
+		_, err := service.deleteComments(ctx, []string{commentID})
+		return err
+	})
+}
+
+// getPostComment returns the comment when it belongs to the post.
+func (service *Service) getPostComment(postID, commentID string) (*model.Comment, error) {
+	comment, err := service.repository.GetComment(commentID)
+	if err != nil || comment.PostID != postID {
+		return nil, errors.CommentNotFound
+	}
+
+	return comment, nil
+}
+
+// hydrateComments fills in the authors, reply counts and reactions of comments as seen by
+// the viewer, with one query each.
+func (service *Service) hydrateComments(viewer model.User, comments []model.Comment) error {
+	var userIDs, commentIDs []string
+	for _, comment := range comments {
+		userIDs = append(userIDs, comment.UserID)
+		commentIDs = append(commentIDs, comment.ID)
+	}
+
+	users, err := service.repository.GetUsersByIDList(userIDs)
+	if err != nil {
+		return err
+	}
+
+	userViews := map[string]*model.PublicUserView{}
+	for _, user := range users {
+		userView := model.NewPublicUserView(user)
+		userViews[user.ID] = &userView
+	}
+
+	// Replies are counted as they are listed, without those of users who blocked the viewer.
+	replyCounts, err := service.repository.GetCommentReplyCounts(commentIDs, viewer.BlockedByUserIDs)
+	if err != nil {
+		return err
+	}
+
+	reactionCounts, err := service.repository.GetReactionCounts(model.ReactionTargetComment, commentIDs)
+	if err != nil {
+		return err
+	}
+	viewerReactions, err := service.repository.GetUserReactionTypes(model.ReactionTargetComment, commentIDs, viewer.ID)
+	if err != nil {
+		return err
+	}
+
+	for i, comment := range comments {
+		comments[i].User = userViews[comment.UserID]
+		comments[i].ReplyCount = replyCounts[comment.ID]
+		comments[i].ReactionCounts = getReactionCounts(reactionCounts[comment.ID])
+		comments[i].ViewerReaction = viewerReactions[comment.ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/storage"
+	"log"
+	"time"
+)
+
+// Deletions remove the dependent documents first and the owning document last. Without
+// transactions a failed deletion therefore leaves the owner in place to be retried, and
+// CleanOrphans removes whatever a failure left behind.
+
+func (service *Service) DeleteAdminUserPost(postID, userID string) error {
+	post, err := service.repository.GetPost(postID)
+	if err != nil || post.UserID != userID {
+		return errors.PostNotFound
+	}
+
+	var mediaKeys []string
+	err = service.repository.WithTransaction(func(ctx context.Context) error {
+		var err error
+		_, mediaKeys, err = service.deletePosts(ctx, []string{postID})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteMediaFiles(mediaKeys)
+
+	return nil
+}
+
+func (service *Service) AdminDeleteUser(authUser model.User, userID string) error {
+	if authUser.ID == userID {
+		return errors.Forbidden
+	}
+
+	_, err := service.repository.GetUser(userID)
+	if err != nil {
+		return errors.UserNotFound
+	}
+
+	var mediaKeys []string
+	err = service.repository.WithTransaction(func(ctx context.Context) error {
+		postIDs, err := service.repository.GetPostIDsByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		_, postMediaKeys, err := service.deletePosts(ctx, postIDs)
+		if err != nil {
+			return err
+		}
+
+		commentIDs, err := service.repository.GetCommentIDsByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if _, err := service.deleteComments(ctx, commentIDs); err != nil {
+			return err
+		}
+
+		if err := service.repository.DeleteUserReactions(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteUserAlbums(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteUserNotifications(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteUserFriendships(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteUserFollows(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteUserSuggestions(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.PullUserReferences(ctx, userID); err != nil {
+			return err
+		}
+		if err := service.repository.DeleteUserTokens(ctx, userID); err != nil {
+			return err
+		}
+		userMediaKeys, err := service.repository.DeleteUserMedia(ctx, userID)
+		if err != nil {
+			return err
+		}
+		mediaKeys = append(postMediaKeys, userMediaKeys...)
+
+		return service.repository.DeleteUser(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteMediaFiles(mediaKeys)
+
+	return nil
+}
+
+// CleanOrphans removes the data left behind by deletions made before cascading existed or
+// interrupted half way, and purges posts whose recovery period has ended.
+func (service *Service) CleanOrphans() (*model.OrphanCleanupReport, error) {
+	report := model.OrphanCleanupReport{}
+
+	var mediaKeys []string
+	err := service.repository.WithTransaction(func(ctx context.Context) error {
+		postIDs, err := service.repository.GetOrphanPostIDs(ctx, time.Now().Add(-PostRecoveryPeriod))
+		if err != nil {
+			return err
+		}
+		report.DeletedPosts, mediaKeys, err = service.deletePosts(ctx, postIDs)
+		if err != nil {
+			return err
+		}
+
+		commentIDs, err := service.repository.GetOrphanCommentIDs(ctx)
+		if err != nil {
+			return err
+		}
+		deletedComments, err := service.deleteComments(ctx, commentIDs)
+		if err != nil {
+			return err
+		}
+		report.DeletedComments += deletedComments
+
+		report.DeletedPostRevisions, err = service.repository.DeleteOrphanPostRevisions(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.DeletedReactions, err = service.repository.DeleteOrphanReactions(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.DeletedFriendships, err = service.repository.DeleteOrphanFriendships(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.DeletedFollows, err = service.repository.DeleteOrphanFollows(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.UpdatedPosts, report.UpdatedUsers, err = service.repository.PullDanglingReferences(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleteMediaFiles(mediaKeys)
+
+	return &report, nil
+}
+
+// deletePosts removes the posts with their comments, reactions, revisions, notifications and
+// the media no other post or profile uses, takes them out of albums and returns how many posts
+// were deleted with the storage keys of the media files to delete once the deletion is committed.
+func (service *Service) deletePosts(ctx context.Context, postIDs []string) (int, []string, error) {
+	if len(postIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	if _, err := service.repository.DeletePostReactions(ctx, postIDs); err != nil {
+		return 0, nil, err
+	}
+	if _, err := service.repository.DeletePostComments(ctx, postIDs); err != nil {
+		return 0, nil, err
+	}
+	if _, err := service.repository.DeletePostRevisions(ctx, postIDs); err != nil {
+		return 0, nil, err
+	}
+	if err := service.repository.PullAlbumPosts(ctx, postIDs); err != nil {
+		return 0, nil, err
+	}
+	if err := service.repository.DeletePostNotifications(ctx, postIDs); err != nil {
+		return 0, nil, err
+	}
+	mediaKeys, err := service.repository.DeletePostMedia(ctx, postIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	deletedPosts, err := service.repository.DeletePosts(ctx, postIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return deletedPosts, mediaKeys, nil
+}
+
+// deleteMediaFiles removes the files once nothing references them anymore. A failure only
+// leaves files behind.
+func deleteMediaFiles(keys []string) {
+	mediaStorage := storage.GetStorage()
+	for _, key := range keys {
+		if err := mediaStorage.Delete(key); err != nil {
+			log.Println("Could not delete media file " + key + ": " + err.Error())
+		}
+	}
+}
+
+// deleteComments removes the comments with all of their replies, their reactions,
+// notifications and references from posts.
+func (service *Service) deleteComments(ctx context.Context, commentIDs []string) (int, error) {
+	if len(commentIDs) == 0 {
+		return 0, nil
+	}
+
+	commentIDs = append([]string{}, commentIDs...)
+	for parentCommentIDs := commentIDs; len(parentCommentIDs) > 0; {
+		replyCommentIDs, err := service.repository.GetReplyCommentIDs(ctx, parentCommentIDs)
+		if err != nil {
+			return 0, err
+		}
+		commentIDs = append(commentIDs, replyCommentIDs...)
+		parentCommentIDs = replyCommentIDs
+	}
+
+	if _, err := service.repository.DeleteCommentReactions(ctx, commentIDs); err != nil {
+		return 0, err
+	}
+	if err := service.repository.PullPostComments(ctx, commentIDs); err != nil {
+		return 0, err
+	}
+	if err := service.repository.DeleteCommentNotifications(ctx, commentIDs); err != nil {
+		return 0, err
+	}
+
+	return service.repository.DeleteComments(ctx, commentIDs)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
+)
+
+// FollowUser makes the user follow the target user, whose posts the user can see then show
+// up in the user's home feed. Unlike a friendship it needs no approval.
+func (service *Service) FollowUser(authUser model.User, userID, targetID string) error {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return err
+	}
+	if targetID == userID {
+		return errors.InvalidFollow
+	}
+	if _, err := service.getVisibleUser(authUser, targetID); err != nil {
+		return err
+	}
+
+	friendship, err := service.repository.GetFriendship(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if friendship != nil && friendship.Status == model.FriendshipBlocked {
+		return errors.Forbidden
+	}
+
+	return service.repository.CreateFollow(model.Follow{
+		ID:         utils.GenerateUUID(8),
+		FollowerID: userID,
+		FolloweeID: targetID,
+		CreatedAt:  time.Now().UTC().Round(time.Second),
+	})
+}
+
+func (service *Service) UnfollowUser(authUser model.User, userID, targetID string) error {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return err
+	}
+
+	return service.repository.DeleteFollow(userID, targetID)
+}
+
+// GetFollowers returns the users following the user, most recent follower first.
+func (service *Service) GetFollowers(authUser model.User, userID string, getFollowsQuery model.GetFollowsQuery) (*model.FollowsCursorResponse, error) {
+	if _, err := service.getVisibleUser(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	followsCursor, err := decodeCursor(getFollowsQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getFollowsQuery.Limit)
+
+	// One extra follow tells whether there is a next page.
+	follows, err := service.repository.GetFollowers(userID, followsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.getFollowsResponse(authUser, follows, limit, true)
+}
+
+// GetFollowing returns the users the user follows, most recently followed first.
+func (service *Service) GetFollowing(authUser model.User, userID string, getFollowsQuery model.GetFollowsQuery) (*model.FollowsCursorResponse, error) {
+	if _, err := service.getVisibleUser(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	followsCursor, err := decodeCursor(getFollowsQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getFollowsQuery.Limit)
+
+	// One extra follow tells whether there is a next page.
+	follows, err := service.repository.GetFollowing(userID, followsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.getFollowsResponse(authUser, follows, limit, false)
+}
+
+// getFollowsResponse returns a page of follows with the other user of each follow filled in,
+// the follower when isFollowers is set and the followee otherwise. Follows of users who
+// blocked the viewer are left out.
+func (service *Service) getFollowsResponse(viewer model.User, follows []model.Follow, limit int, isFollowers bool) (*model.FollowsCursorResponse, error) {
+	response := model.FollowsCursorResponse{Follows: []model.Follow{}}
+	if len(follows) > limit {
+		follows = follows[:limit]
+		lastFollow := follows[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastFollow.CreatedAt, lastFollow.ID)
+	}
+
+	var userIDs []string
+	for _, follow := range follows {
+		if isFollowers {
+			userIDs = append(userIDs, follow.FollowerID)
+		} else {
+			userIDs = append(userIDs, follow.FolloweeID)
+		}
+	}
+	users, err := service.repository.GetUsersByIDList(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	userViews := map[string]*model.PublicUserView{}
+	for _, user := range users {
+		userView := model.NewPublicUserView(user)
+		userViews[user.ID] = &userView
+	}
+
+	for i, follow := range follows {
+		userID := userIDs[i]
+		if userViews[userID] == nil || utils.Contains(viewer.BlockedByUserIDs, userID) {
+			continue
+		}
+		follow.User = userViews[userID]
+		response.Follows = append(response.Follows, follow)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/model"
+)
+
+const (
+	defaultConnectionDepth = 2
+	// maxConnectionDepth bounds the friendship graph walks, which grow quickly with every hop.
+	maxConnectionDepth = 3
+)
+
+// GetMutualFriends returns the friends the user and the viewer have in common.
+func (service *Service) GetMutualFriends(authUser model.User, userID string) ([]model.User, error) {
+	if _, err := service.getVisibleUser(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	return service.repository.GetMutualFriends(authUser.FriendIDs, userID)
+}
+
+// GetConnections returns the users at most the query depth of friendships away from the user,
+// friends first, then friends of friends and so on.
+func (service *Service) GetConnections(authUser model.User, userID string, getConnectionsQuery model.GetConnectionsQuery) ([]model.Connection, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	blockedUserIDs, err := service.getBlockedUserIDs(authUser)
+	if err != nil {
+		return nil, err
+	}
+
+	connections, err := service.repository.GetConnections(userID, getConnectionDepth(getConnectionsQuery.Depth), blockedUserIDs, getPageLimit(getConnectionsQuery.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []string
+	for _, connection := range connections {
+		userIDs = append(userIDs, connection.UserID)
+	}
+	users, err := service.repository.GetUsersByIDList(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	userViews := map[string]*model.PublicUserView{}
+	for _, user := range users {
+		userView := model.NewPublicUserView(user)
+		userViews[user.ID] = &userView
+	}
+
+	userConnections := []model.Connection{}
+	for _, connection := range connections {
+		if userViews[connection.UserID] == nil {
+			continue
+		}
+		connection.User = userViews[connection.UserID]
+		userConnections = append(userConnections, connection)
+	}
+
+	return userConnections, nil
+}
+
+// GetConnection returns the degree of separation between the user and the target user, which
+// is searched up to the query depth.
+func (service *Service) GetConnection(authUser model.User, userID, targetID string, getConnectionsQuery model.GetConnectionsQuery) (*model.Connection, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	targetUser, err := service.getVisibleUser(authUser, targetID)
+	if err != nil {
+		return nil, err
+	}
+	targetView := model.NewPublicUserView(*targetUser)
+
+	if targetID == userID {
+		return &model.Connection{UserID: targetID, User: &targetView}, nil
+	}
+
+	connection, err := service.repository.GetConnection(userID, targetID, getConnectionDepth(getConnectionsQuery.Depth))
+	if err != nil {
+		return nil, err
+	}
+	connection.User = &targetView
+
+	return connection, nil
+}
+
+func getConnectionDepth(depth int) int {
+	if depth <= 0 {
+		return defaultConnectionDepth
+	}
+	if depth > maxConnectionDepth {
+		return maxConnectionDepth
+	}
+	return depth
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
+)
+
+// SendFriendRequest sends a friend request from the user to the target user and returns
+// the target user. A request can be sent again once the previous one was declined or
+// cancelled.
+func (service *Service) SendFriendRequest(authUser model.User, targetUserID string, friendRequestDTO model.FriendRequestDTO) (*model.User, error) {
+	if len(friendRequestDTO.UserID) != 0 {
+		if err := checkOwnership(authUser, friendRequestDTO.UserID); err != nil {
+			return nil, err
+		}
+	}
+	if targetUserID == authUser.ID {
+		return nil, errors.InvalidFriendRequest
+	}
+
+	targetUser, err := service.getVisibleUser(authUser, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	friendship, err := service.repository.GetFriendship(authUser.ID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if friendship != nil {
+		switch friendship.Status {
+		case model.FriendshipAccepted:
+			return nil, errors.AlreadyFriends
+		case model.FriendshipPending:
+			return nil, errors.FriendRequestExists
+		case model.FriendshipBlocked:
+			return nil, errors.Forbidden
+		}
+	}
+
+	now := time.Now().UTC().Round(time.Second)
+	err = service.repository.CreateFriendRequest(model.Friendship{
+		ID:          utils.GenerateUUID(8),
+		RequesterID: authUser.ID,
+		AddresseeID: targetUserID,
+		Status:      model.FriendshipPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return targetUser, nil
+}
+
+// GetUserFriendRequests returns the users who sent the user a friend request, newest first.
+func (service *Service) GetUserFriendRequests(authUser model.User, userID string) ([]model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	friendships, err := service.repository.GetIncomingFriendRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var requesterIDs []string
+	for _, friendship := range friendships {
+		requesterIDs = append(requesterIDs, friendship.RequesterID)
+	}
+
+	return service.getUsersInOrder(requesterIDs)
+}
+
+// GetUserSentFriendRequests returns the users the user sent a friend request to that are
+// still pending, newest first.
+func (service *Service) GetUserSentFriendRequests(authUser model.User, userID string) ([]model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	friendships, err := service.repository.GetOutgoingFriendRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresseeIDs []string
+	for _, friendship := range friendships {
+		addresseeIDs = append(addresseeIDs, friendship.AddresseeID)
+	}
+
+	return service.getUsersInOrder(addresseeIDs)
+}
+
+func (service *Service) AcceptOrDeclineUserFriendRequest(authUser model.User, userID, targetID string, acceptOrDeclineFriendRequestDTO model.AcceptOrDeclineFriendRequestDTO) (*model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.repository.GetUser(targetID); err != nil {
+		return nil, errors.UserNotFound
+	}
+
+	now := time.Now().UTC().Round(time.Second)
+	err := service.repository.WithTransaction(func(ctx context.Context) error {
+		if !acceptOrDeclineFriendRequestDTO.Accept {
+			return service.repository.UpdateFriendshipStatus(ctx, targetID, userID, model.FriendshipPending, model.FriendshipDeclined, now)
+		}
+
+		err := service.repository.UpdateFriendshipStatus(ctx, targetID, userID, model.FriendshipPending, model.FriendshipAccepted, now)
+		if err != nil {
+			return err
+		}
+
+		return service.repository.AddFriendIDs(ctx, userID, targetID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return service.repository.GetUser(userID)
+}
+
+// CancelFriendRequest withdraws the pending friend request the user sent to the target user.
+func (service *Service) CancelFriendRequest(authUser model.User, userID, targetID string) error {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return err
+	}
+
+	return service.repository.WithTransaction(func(ctx context.Context) error {
+		return service.repository.UpdateFriendshipStatus(ctx, userID, targetID, model.FriendshipPending, model.FriendshipCancelled, time.Now().UTC().Round(time.Second))
+	})
+}
+
+func (service *Service) GetUserFriends(authUser model.User, userID string) ([]model.User, error) {
+	user, err := service.getVisibleUser(authUser, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friends, err := service.repository.GetUsersByIDList(user.FriendIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return friends, nil
+}
+
+func (service *Service) DeleteUserFriend(authUser model.User, userID, friendID string) (*model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.repository.GetUser(friendID); err != nil {
+		return nil, errors.UserNotFound
+	}
+
+	err := service.repository.WithTransaction(func(ctx context.Context) error {
+		if err := service.repository.DeleteFriendship(ctx, userID, friendID); err != nil {
+			return err
+		}
+
+		return service.repository.RemoveFriendIDs(ctx, userID, friendID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return service.repository.GetUser(userID)
+}
+
+// getUsersInOrder returns the users with the IDs in the same order, leaving out users that
+// no longer exist.
+func (service *Service) getUsersInOrder(userIDs []string) ([]model.User, error) {
+	users, err := service.repository.GetUsersByIDList(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByID := map[string]model.User{}
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	var orderedUsers []model.User
+	for _, userID := range userIDs {
+		if user, ok := usersByID[userID]; ok {
+			orderedUsers = append(orderedUsers, user)
+		}
+	}
+
+	return orderedUsers, nil
+}
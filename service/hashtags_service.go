@@ -0,0 +1,133 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"log"
+	"time"
+)
+
+const (
+	defaultTrendingHours = 24
+	maxTrendingHours     = 7 * 24
+)
+
+func (service *Service) GetHashtagPosts(authUser model.User, hashtag string, getHashtagPostsQuery model.GetHashtagPostsQuery) (*model.PostsCursorResponse, error) {
+	postsCursor, err := decodeCursor(getHashtagPostsQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getHashtagPostsQuery.Limit)
+
+	// One extra post tells whether there is a next page.
+	posts, err := service.repository.GetHashtagPosts(authUser, utils.NormalizeHashtag(hashtag), postsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.PostsCursorResponse{Posts: []model.Post{}}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		lastPost := posts[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastPost.CreatedAt, lastPost.ID)
+	}
+
+	if err := service.hydratePosts(authUser, posts); err != nil {
+		return nil, err
+	}
+	if posts != nil {
+		response.Posts = posts
+	}
+
+	return &response, nil
+}
+
+// GetTrendingHashtags returns the hashtags used the most in the last hours, 24 by default and
+// at most a week.
+func (service *Service) GetTrendingHashtags(getTrendingHashtagsQuery model.GetTrendingHashtagsQuery) ([]model.TrendingHashtag, error) {
+	hours := getTrendingHashtagsQuery.Hours
+	if hours <= 0 {
+		hours = defaultTrendingHours
+	}
+	if hours > maxTrendingHours {
+		hours = maxTrendingHours
+	}
+
+	since := time.Now().UTC().Add(-time.Duration(hours) * time.Hour)
+	trendingHashtags, err := service.repository.GetTrendingHashtags(since, getPageLimit(getTrendingHashtagsQuery.Limit))
+	if err != nil {
+		return nil, err
+	}
+	if trendingHashtags == nil {
+		trendingHashtags = []model.TrendingHashtag{}
+	}
+
+	return trendingHashtags, nil
+}
+
+// resolveMentions returns the users mentioned in text. Mentions of unknown usernames are ignored.
+func (service *Service) resolveMentions(text string) ([]model.User, error) {
+	usernames := utils.ExtractMentions(text)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	return service.repository.GetUsersByUsernames(usernames)
+}
+
+func getUserIDs(users []model.User) []string {
+	var userIDs []string
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+	return userIDs
+}
+
+// notifyMentions tells the mentioned users that the actor mentioned them in the post, or in
+// one of its comments when commentID is set. Users mentioned before an edit, the actor and
+// users who cannot see the post are not notified. The post is already saved, so a failure is
+// only logged.
+func (service *Service) notifyMentions(actor model.User, post model.Post, commentID string, mentionedUsers []model.User, previouslyMentionedIDs []string) {
+	var notifications []model.Notification
+	for _, mentionedUser := range mentionedUsers {
+		if mentionedUser.ID == actor.ID || utils.Contains(previouslyMentionedIDs, mentionedUser.ID) || !canViewPost(mentionedUser, post) {
+			continue
+		}
+
+		notifications = append(notifications, model.Notification{
+			ID:        utils.GenerateUUID(8),
+			UserID:    mentionedUser.ID,
+			Type:      model.NotificationMention,
+			ActorID:   actor.ID,
+			PostID:    post.ID,
+			CommentID: commentID,
+			CreatedAt: time.Now().UTC().Round(time.Second),
+		})
+	}
+
+	if err := service.repository.CreateNotifications(notifications); err != nil {
+		log.Println("Could not create mention notifications: " + err.Error())
+	}
+}
+
+// BackfillHashtags extracts the hashtags of the posts and comments saved before hashtags were
+// extracted and returns how many documents were updated.
+func (service *Service) BackfillHashtags() (int, error) {
+	updated := 0
+	textFields := map[string]string{"posts": "description", "comments": "content"}
+	for collectionName, textField := range textFields {
+		texts, err := service.repository.GetUntaggedTexts(collectionName, textField)
+		if err != nil {
+			return updated, err
+		}
+
+		for id, text := range texts {
+			if err := service.repository.SetHashtags(collectionName, id, utils.ExtractHashtags(text)); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+
+	return updated, nil
+}
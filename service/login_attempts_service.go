@@ -0,0 +1,142 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// Failures older than this are forgotten unless the key is still locked.
+	attemptWindow = time.Hour
+
+	accountLoginAttemptLimit   = 5
+	ipLoginAttemptLimit        = 20
+	accountForgotPasswordLimit = 3
+	ipForgotPasswordLimit      = 10
+
+	baseLockDuration = time.Minute
+	maxLockDuration  = 24 * time.Hour
+)
+
+func accountLoginKey(email string) string {
+	return "login:account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLoginKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+func accountForgotPasswordKey(email string) string {
+	return "forgotPassword:account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipForgotPasswordKey(ip string) string {
+	return "forgotPassword:ip:" + ip
+}
+
+// lockDuration doubles with every failure past the limit, up to maxLockDuration.
+func lockDuration(failedCount, limit int) time.Duration {
+	exponent := failedCount - limit
+	if exponent > 10 {
+		return maxLockDuration
+	}
+
+	duration := baseLockDuration << exponent
+	if duration > maxLockDuration {
+		return maxLockDuration
+	}
+
+	return duration
+}
+
+func (service *Service) checkAttemptLocks(keys ...string) error {
+	now := time.Now().UTC()
+	for _, key := range keys {
+		attempt, err := service.repository.GetLoginAttempt(key)
+		if err != nil {
+			return err
+		}
+
+		if attempt != nil && now.Before(attempt.LockedUntil) {
+			return errors.TooManyAttempts
+		}
+	}
+
+	return nil
+}
+
+// recordFailedAttempt counts a failure for the key and locks it once the limit is reached.
+func (service *Service) recordFailedAttempt(key string, limit int, userID, ip string) error {
+	now := time.Now().UTC()
+
+	attempt, err := service.repository.IncrementLoginAttempt(key, now, now.Add(-attemptWindow))
+	if err != nil {
+		return err
+	}
+
+	if attempt.FailedCount < limit {
+		return nil
+	}
+
+	lockedUntil := now.Add(lockDuration(attempt.FailedCount, limit))
+	isLocked, err := service.repository.LockLoginAttempt(key, lockedUntil)
+	if err != nil || !isLocked {
+		return err
+	}
+
+	return service.repository.CreateAuditEvent(model.AuditEvent{
+		ID:          utils.GenerateUUID(8),
+		Type:        model.AuditLock,
+		Key:         key,
+		UserID:      userID,
+		IPAddress:   ip,
+		LockedUntil: lockedUntil,
+		CreatedAt:   now,
+	})
+}
+
+// resetAttempts forgets the failures of the key, recording an unlock event if it had been locked.
+func (service *Service) resetAttempts(key, userID, ip string) error {
+	attempt, err := service.repository.DeleteLoginAttempt(key)
+	if err != nil || attempt == nil {
+		return err
+	}
+
+	if attempt.LockedUntil.IsZero() {
+		return nil
+	}
+
+	return service.repository.CreateAuditEvent(model.AuditEvent{
+		ID:        utils.GenerateUUID(8),
+		Type:      model.AuditUnlock,
+		Key:       key,
+		UserID:    userID,
+		IPAddress: ip,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (service *Service) AdminUnlockUser(userID string) error {
+	user, err := service.repository.GetUser(userID)
+	if err != nil {
+		return errors.UserNotFound
+	}
+
+	err = service.resetAttempts(accountLoginKey(user.Email), user.ID, "")
+	if err != nil {
+		return err
+	}
+
+	return service.resetAttempts(accountForgotPasswordKey(user.Email), user.ID, "")
+}
+
+func (service *Service) GetAuditEvents() ([]model.AuditEvent, error) {
+	return service.repository.GetAuditEvents()
+}
+
+func (service *Service) ClearAuditEvents() error {
+	return service.repository.DeleteAuditEvents()
+}
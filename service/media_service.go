@@ -0,0 +1,197 @@
+package service
+
+import (
+	"encoding/base64"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/storage"
+	"github.com/anilaydinn/socium-be/utils"
+)
+
+// UploadMedia stores an uploaded image with its thumbnail and returns the media that posts
+// and profiles reference.
+func (service *Service) UploadMedia(authUser model.User, data []byte) (*model.Media, error) {
+	if len(data) > utils.GetMaxUploadSize() {
+		return nil, errors.MediaTooLarge
+	}
+
+	media, err := service.storeImage(authUser.ID, data)
+	if err != nil {
+		return nil, err
+	}
+	signMediaURLs(media)
+
+	return media, nil
+}
+
+// GetMediaFile returns the original file of the media, or its thumbnail, with its content type.
+// Files are only returned for URLs signed when the media was handed out to a user allowed to
+// see it, except profile images, which anyone can see.
+func (service *Service) GetMediaFile(mediaID string, thumbnail bool, expires, signature string) ([]byte, string, error) {
+	media, err := service.repository.GetMedia(mediaID)
+	if err != nil {
+		return nil, "", errors.MediaNotFound
+	}
+
+	url, key, contentType := media.URL, media.Key, media.ContentType
+	if thumbnail {
+		url, key, contentType = media.ThumbnailURL, media.ThumbnailKey, media.ThumbnailContentType
+	}
+
+	if !utils.VerifyMediaURL(url, expires, signature) {
+		isProfileImage, err := service.repository.IsProfileImage(mediaID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !isProfileImage {
+			return nil, "", errors.Forbidden
+		}
+	}
+
+	data, _, err := storage.GetStorage().Get(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, contentType, nil
+}
+
+// MigrateInlineImages moves the images stored as base64 inside posts and users to the
+// storage and replaces them with media references. It returns the number of images moved.
+func (service *Service) MigrateInlineImages() (int, error) {
+	sources := []struct {
+		collectionName string
+		imageField     string
+		ownerField     string
+	}{
+		{collectionName: "posts", imageField: "image", ownerField: "userId"},
+		{collectionName: "users", imageField: "profileImage", ownerField: "id"},
+	}
+
+	migratedImages := 0
+	for _, source := range sources {
+		inlineImages, err := service.repository.GetInlineImages(source.collectionName, source.imageField, source.ownerField)
+		if err != nil {
+			return migratedImages, err
+		}
+
+		for _, inlineImage := range inlineImages {
+			media, err := service.storeImage(inlineImage.UserID, decodeInlineImage(inlineImage.Data))
+			if err == errors.UnsupportedMediaType || err == errors.MediaTooLarge {
+				log.Println("Skipping the image of " + source.collectionName + " " + inlineImage.ID + ": " + err.Error())
+				continue
+			}
+			if err != nil {
+				return migratedImages, err
+			}
+
+			err = service.repository.ReplaceInlineImage(source.collectionName, source.imageField, inlineImage.ID, inlineImage.Data, media.ID)
+			if err != nil {
+				return migratedImages, err
+			}
+			migratedImages++
+		}
+	}
+
+	return migratedImages, nil
+}
+
+func (service *Service) storeImage(userID string, data []byte) (*model.Media, error) {
+	processedImage, err := utils.ProcessImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaID := utils.GenerateUUID(0)
+	media := model.Media{
+		ID:                   mediaID,
+		UserID:               userID,
+		ContentType:          processedImage.ContentType,
+		Size:                 len(processedImage.Data),
+		Width:                processedImage.Width,
+		Height:               processedImage.Height,
+		Key:                  "media/" + mediaID + "/original",
+		ThumbnailKey:         "media/" + mediaID + "/thumbnail",
+		ThumbnailContentType: processedImage.ThumbnailContentType,
+		CreatedAt:            time.Now().UTC().Round(time.Second),
+	}
+
+	mediaStorage := storage.GetStorage()
+	if err := mediaStorage.Put(media.Key, media.ContentType, processedImage.Data); err != nil {
+		return nil, err
+	}
+	if err := mediaStorage.Put(media.ThumbnailKey, media.ThumbnailContentType, processedImage.Thumbnail); err != nil {
+		return nil, err
+	}
+
+	return service.repository.CreateMedia(media)
+}
+
+// getMediaByID returns the media with the given IDs by ID, with a single query. Their URLs are
+// signed, so callers only hand them out to users allowed to see the posts they are in.
+func (service *Service) getMediaByID(mediaIDs []string) (map[string]*model.Media, error) {
+	mediaByID := map[string]*model.Media{}
+	if len(mediaIDs) == 0 {
+		return mediaByID, nil
+	}
+
+	media, err := service.repository.GetMediaByIDList(mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range media {
+		signMediaURLs(&media[i])
+		mediaByID[media[i].ID] = &media[i]
+	}
+
+	return mediaByID, nil
+}
+
+// signMediaURLs lets whoever the media is handed out to fetch its files for a while. The expiry
+// is rounded, so the URLs stay the same for a while and browsers can cache the files.
+func signMediaURLs(media *model.Media) {
+	ttl := utils.GetMediaURLTTL()
+	expiresAt := time.Now().Truncate(ttl).Add(2 * ttl)
+
+	media.URL = utils.SignMediaURL(media.URL, expiresAt)
+	media.ThumbnailURL = utils.SignMediaURL(media.ThumbnailURL, expiresAt)
+}
+
+// checkMediaReference accepts an empty reference or the ID of media the user uploaded.
+func (service *Service) checkMediaReference(authUser model.User, mediaID string) error {
+	if len(mediaID) == 0 {
+		return nil
+	}
+
+	media, err := service.repository.GetMedia(mediaID)
+	if err != nil || media.UserID != authUser.ID {
+		return errors.InvalidMedia
+	}
+
+	return nil
+}
+
+// decodeInlineImage returns the bytes of a base64 image, with or without a data URL prefix.
+// Data that is not base64 is returned as is and rejected as an unsupported image.
+func decodeInlineImage(image string) []byte {
+	if strings.HasPrefix(image, "data:") {
+		if i := strings.Index(image, ","); i >= 0 {
+			image = image[i+1:]
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(image)
+	if err != nil {
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(image, "="))
+	}
+	if err != nil {
+		return []byte(image)
+	}
+
+	return data
+}
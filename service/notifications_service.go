@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+)
+
+func (service *Service) GetNotifications(authUser model.User, getNotificationsQuery model.GetNotificationsQuery) (*model.NotificationsCursorResponse, error) {
+	notificationsCursor, err := decodeCursor(getNotificationsQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getNotificationsQuery.Limit)
+
+	// One extra notification tells whether there is a next page.
+	notifications, err := service.repository.GetNotifications(authUser.ID, notificationsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.NotificationsCursorResponse{Notifications: []model.Notification{}}
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		lastNotification := notifications[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastNotification.CreatedAt, lastNotification.ID)
+	}
+
+	var actorIDs []string
+	for _, notification := range notifications {
+		if !utils.Contains(actorIDs, notification.ActorID) {
+			actorIDs = append(actorIDs, notification.ActorID)
+		}
+	}
+	actors, err := service.repository.GetUsersByIDList(actorIDs)
+	if err != nil {
+		return nil, err
+	}
+	actorViews := map[string]*model.PublicUserView{}
+	for _, actor := range actors {
+		actorView := model.NewPublicUserView(actor)
+		actorViews[actor.ID] = &actorView
+	}
+	for i := range notifications {
+		notifications[i].Actor = actorViews[notifications[i].ActorID]
+	}
+
+	if notifications != nil {
+		response.Notifications = notifications
+	}
+
+	return &response, nil
+}
+
+func (service *Service) MarkNotificationsRead(authUser model.User) error {
+	return service.repository.MarkNotificationsRead(authUser.ID)
+}
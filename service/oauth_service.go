@@ -0,0 +1,149 @@
+package service
+
+import (
+	"crypto/subtle"
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	"log"
+	"time"
+)
+
+const OAuthStateTTL = 10 * time.Minute
+
+// StartOAuthLogin returns the provider URL the user is redirected to and the state that
+// has to come back with the callback.
+func (service *Service) StartOAuthLogin(providerName string) (string, string, error) {
+	provider, ok := auth.GetOAuthProvider(providerName)
+	if !ok {
+		return "", "", errors.OAuthProviderNotFound
+	}
+
+	state := utils.GenerateSecureToken(32)
+	codeVerifier, err := auth.GeneratePKCEVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = service.repository.CreateOAuthState(model.OAuthState{
+		ID:           utils.GenerateUUID(8),
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().UTC().Add(OAuthStateTTL),
+		CreatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return provider.AuthCodeURL(state, auth.PKCEChallenge(codeVerifier)), state, nil
+}
+
+// CompleteOAuthLogin handles the provider callback. The state has to match the one stored
+// in the user's browser when the login started.
+func (service *Service) CompleteOAuthLogin(providerName, code, state, browserState string) (*model.Token, *fiber.Cookie, error) {
+	provider, ok := auth.GetOAuthProvider(providerName)
+	if !ok {
+		return nil, nil, errors.OAuthProviderNotFound
+	}
+
+	if len(state) == 0 || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, nil, errors.InvalidOAuthState
+	}
+
+	oauthState, err := service.repository.ConsumeOAuthState(utils.HashToken(state))
+	if err != nil {
+		return nil, nil, errors.InvalidOAuthState
+	}
+
+	if oauthState.Provider != providerName || time.Now().UTC().After(oauthState.ExpiresAt) {
+		return nil, nil, errors.InvalidOAuthState
+	}
+
+	if len(code) == 0 {
+		return nil, nil, errors.OAuthExchangeFailed
+	}
+
+	userInfo, err := provider.Exchange(code, oauthState.CodeVerifier)
+	if err != nil {
+		log.Println(err)
+		return nil, nil, errors.OAuthExchangeFailed
+	}
+
+	user, err := service.getOrCreateOAuthUser(providerName, *userInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.IsBanned {
+		return nil, nil, errors.UserBanned
+	}
+
+	return service.completeLogin(*user, false)
+}
+
+// getOrCreateOAuthUser finds the user linked to the external identity. Identities are only
+// linked to an existing account, or used to register a new one, when the provider verified the email.
+// Accounts that were never activated are not linked, since whoever registered them may not own the
+// address. Linking keeps the password but ends every session, so sessions opened by whoever knew the
+// password before the owner proved the address do not outlive the link.
+func (service *Service) getOrCreateOAuthUser(providerName string, userInfo auth.OAuthUserInfo) (*model.User, error) {
+	user, err := service.repository.GetUserByExternalIdentity(providerName, userInfo.Subject)
+	if err == nil {
+		return user, nil
+	}
+
+	if !userInfo.EmailVerified || len(userInfo.Email) == 0 {
+		return nil, errors.EmailNotVerified
+	}
+
+	externalIdentity := model.ExternalIdentity{
+		Provider: providerName,
+		Subject:  userInfo.Subject,
+		Email:    userInfo.Email,
+		LinkedAt: time.Now().UTC(),
+	}
+
+	user, _ = service.repository.GetUserByEmail(userInfo.Email)
+	if user != nil {
+		if !user.IsActivated {
+			return nil, errors.UserNotActivated
+		}
+
+		user.ExternalIdentities = append(user.ExternalIdentities, externalIdentity)
+		user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+		_, err = service.repository.UpdateUser(user.ID, *user)
+		if err != nil {
+			return nil, err
+		}
+
+		err = service.LogoutAll(user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.repository.GetUser(user.ID)
+	}
+
+	username, err := service.generateUsername(userInfo.GivenName, userInfo.FamilyName)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.repository.RegisterUser(model.User{
+		ID:                 utils.GenerateUUID(8),
+		Name:               userInfo.GivenName,
+		Surname:            userInfo.FamilyName,
+		Username:           username,
+		Email:              userInfo.Email,
+		UserType:           model.RoleUser,
+		IsActivated:        true,
+		ExternalIdentities: []model.ExternalIdentity{externalIdentity},
+		CreatedAt:          time.Now().UTC().Round(time.Minute),
+		UpdatedAt:          time.Now().UTC().Round(time.Minute),
+	})
+}
@@ -7,135 +7,518 @@ import (
 	"time"
 )
 
-func (service *Service) CreatePost(postDTO model.PostDTO) (*model.Post, error) {
+func (service *Service) CreatePost(authUser model.User, postDTO model.PostDTO) (*model.Post, error) {
+	if len(postDTO.UserID) != 0 {
+		if err := checkOwnership(authUser, postDTO.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	media, err := resolvePostMedia(postDTO.Image, postDTO.Media)
+	if err != nil {
+		return nil, err
+	}
+	for _, postMedia := range media {
+		if err := service.checkMediaReference(authUser, postMedia.MediaID); err != nil {
+			return nil, err
+		}
+	}
+
+	audience := postDTO.Audience
+	if len(audience) == 0 {
+		audience = model.GetLegacyAudience(postDTO.IsPrivate)
+	}
+	audience, audienceUserIDs, err := resolveAudience(audience, postDTO.AudienceUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	mentionedUsers, err := service.resolveMentions(postDTO.Description)
+	if err != nil {
+		return nil, err
+	}
+
 	post := model.Post{
-		ID:          utils.GenerateUUID(8),
-		UserID:      postDTO.UserID,
-		Description: postDTO.Description,
-		Image:       postDTO.Image,
-		IsPrivate:   postDTO.IsPrivate,
-		CreatedAt:   time.Now().UTC().Round(time.Second),
-		UpdatedAt:   time.Now().UTC().Round(time.Second),
+		ID:               utils.GenerateUUID(8),
+		UserID:           authUser.ID,
+		Description:      postDTO.Description,
+		Image:            getPostImage(media),
+		Media:            media,
+		Hashtags:         utils.ExtractHashtags(postDTO.Description),
+		MentionedUserIDs: getUserIDs(mentionedUsers),
+		IsPrivate:        audience != model.AudiencePublic,
+		Audience:         audience,
+		AudienceUserIDs:  audienceUserIDs,
+		CreatedAt:        time.Now().UTC().Round(time.Second),
+		UpdatedAt:        time.Now().UTC().Round(time.Second),
+	}
+
+	newPost, err := service.repository.CreatePost(post)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyMentions(authUser, *newPost, "", mentionedUsers, nil)
+
+	return newPost, nil
+}
+
+// PostRecoveryPeriod is how long a deleted post can be restored by its owner.
+const PostRecoveryPeriod = 30 * 24 * time.Hour
+
+// Posts only come with their first top level comments, the rest are paged through GetPostComments.
+const postCommentPreviewLimit = 3
+
+// resolvePostMedia returns the media items of a post in the order they were sent, or the
+// single image sent by clients that predate media lists.
+func resolvePostMedia(image string, mediaDTOs []model.PostMediaDTO) ([]model.PostMedia, error) {
+	if len(mediaDTOs) == 0 && len(image) != 0 {
+		mediaDTOs = []model.PostMediaDTO{{MediaID: image}}
+	}
+	if len(mediaDTOs) > model.MaxPostMedia {
+		return nil, errors.InvalidMedia
+	}
+
+	var media []model.PostMedia
+	var mediaIDs []string
+	for _, mediaDTO := range mediaDTOs {
+		if len(mediaDTO.MediaID) == 0 || utils.Contains(mediaIDs, mediaDTO.MediaID) {
+			return nil, errors.InvalidMedia
+		}
+		mediaIDs = append(mediaIDs, mediaDTO.MediaID)
+
+		media = append(media, model.PostMedia{
+			MediaID: mediaDTO.MediaID,
+			Caption: mediaDTO.Caption,
+			AltText: mediaDTO.AltText,
+		})
+	}
+
+	return media, nil
+}
+
+// getPostImage returns the first media item, which clients showing a single image display.
+func getPostImage(media []model.PostMedia) string {
+	if len(media) == 0 {
+		return ""
 	}
+	return media[0].MediaID
+}
 
-	return service.repository.CreatePost(post)
+func resolveAudience(audience string, audienceUserIDs []string) (string, []string, error) {
+	if !utils.Contains(model.Audiences, audience) {
+		return "", nil, errors.InvalidAudience
+	}
+	if audience != model.AudienceCustom {
+		return audience, nil, nil
+	}
+	if len(audienceUserIDs) == 0 {
+		return "", nil, errors.InvalidAudience
+	}
+
+	return audience, audienceUserIDs, nil
 }
 
-func (service *Service) GetPosts(userID string, isHomePage bool, friendIDList []string) ([]model.Post, error) {
+func (service *Service) GetPosts(authUser model.User, userID string, isHomePage bool, cursor string, limit int) (*model.PostsCursorResponse, error) {
+	if len(userID) == 0 {
+		userID = authUser.ID
+	}
+
+	var friendIDList []string
 	if isHomePage {
-		friendIDList = append(friendIDList, userID)
+		if err := checkOwnership(authUser, userID); err != nil {
+			return nil, err
+		}
+		followingIDs, err := service.repository.GetFollowingIDs(authUser.ID)
+		if err != nil {
+			return nil, err
+		}
+		// The audience filter of the query keeps the posts the viewer may see, so followed
+		// users who are not friends only show up with their public posts.
+		authorIDs := append(append([]string{}, authUser.FriendIDs...), followingIDs...)
+		for _, authorID := range authorIDs {
+			if !utils.Contains(authUser.MutedUserIDs, authorID) && !utils.Contains(friendIDList, authorID) {
+				friendIDList = append(friendIDList, authorID)
+			}
+		}
+		friendIDList = append(friendIDList, authUser.ID)
 	}
 
-	posts, err := service.repository.GetPosts(userID, isHomePage, friendIDList)
+	postsCursor, err := decodeCursor(cursor)
 	if err != nil {
 		return nil, err
 	}
+	limit = getPageLimit(limit)
+
+	// One extra post tells whether there is a next page.
+	posts, err := service.repository.GetPosts(&authUser, userID, isHomePage, friendIDList, postsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.PostsCursorResponse{Posts: []model.Post{}}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		lastPost := posts[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastPost.CreatedAt, lastPost.ID)
+	}
+
+	if err := service.hydratePosts(authUser, posts); err != nil {
+		return nil, err
+	}
+	if posts != nil {
+		response.Posts = posts
+	}
+
+	return &response, nil
+}
+
+// hydratePosts fills in the authors, comments, media and reactions of posts as seen by the viewer,
+// with one query for each kind whatever the number of posts.
+func (service *Service) hydratePosts(viewer model.User, posts []model.Post) error {
+	var postIDs, userIDs, mediaIDs []string
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+		userIDs = append(userIDs, post.UserID)
+		for _, postMedia := range post.Media {
+			mediaIDs = append(mediaIDs, postMedia.MediaID)
+		}
+	}
+
+	users, err := service.repository.GetUsersByIDList(userIDs)
+	if err != nil {
+		return err
+	}
+
+	userViews := map[string]*model.PublicUserView{}
+	for _, user := range users {
+		userView := model.NewPublicUserView(user)
+		userViews[user.ID] = &userView
+	}
+
+	// Users who blocked the viewer are hidden from them.
+	commentCounts, err := service.repository.GetPostCommentCounts(postIDs, viewer.BlockedByUserIDs)
+	if err != nil {
+		return err
+	}
+	comments, err := service.repository.GetPostCommentPreviews(postIDs, viewer.BlockedByUserIDs, postCommentPreviewLimit)
+	if err != nil {
+		return err
+	}
+	if err := service.hydrateComments(viewer, comments); err != nil {
+		return err
+	}
+
+	commentsByPostID := map[string][]model.Comment{}
+	for _, comment := range comments {
+		commentsByPostID[comment.PostID] = append(commentsByPostID[comment.PostID], comment)
+	}
+
+	reactionCounts, err := service.repository.GetReactionCounts(model.ReactionTargetPost, postIDs)
+	if err != nil {
+		return err
+	}
+	viewerReactions, err := service.repository.GetUserReactionTypes(model.ReactionTargetPost, postIDs, viewer.ID)
+	if err != nil {
+		return err
+	}
+
+	mediaByID, err := service.getMediaByID(mediaIDs)
+	if err != nil {
+		return err
+	}
 
 	for i, post := range posts {
-		postUser, err := service.repository.GetUser(post.UserID)
-		if err != nil {
+		posts[i].User = userViews[post.UserID]
+		for j, postMedia := range post.Media {
+			posts[i].Media[j].Media = mediaByID[postMedia.MediaID]
+		}
+		posts[i].ReactionCounts = getReactionCounts(reactionCounts[post.ID])
+		posts[i].ViewerReaction = viewerReactions[post.ID]
+		posts[i].Comments = commentsByPostID[post.ID]
+		posts[i].CommentCount = commentCounts[post.ID]
+	}
+
+	return nil
+}
+
+func (service *Service) LikePost(authUser model.User, postID string, likePostDTO model.LikePostDTO) (*model.Post, error) {
+	if len(likePostDTO.UserID) != 0 {
+		if err := checkOwnership(authUser, likePostDTO.UserID); err != nil {
 			return nil, err
 		}
-		posts[i].User = postUser
+	}
 
-		comments, err := service.repository.GetCommentsByIDList(post.CommentIDs)
+	post, err := service.getVisiblePost(authUser, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	unliked, err := service.repository.DeleteReactionOfType(model.ReactionTargetPost, postID, authUser.ID, model.ReactionLike)
+	if err != nil {
+		return nil, err
+	}
+	if !unliked {
+		err = service.setReaction(authUser, model.ReactionTargetPost, postID, postID, model.ReactionLike)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		for j, comment := range comments {
-			commentUser, err := service.repository.GetUser(comment.UserID)
-			if err != nil {
-				return nil, err
-			}
-			comments[j].User = commentUser
-		}
-		posts[i].Comments = comments
+	posts := []model.Post{*post}
+	if err := service.hydratePosts(authUser, posts); err != nil {
+		return nil, err
 	}
 
-	return posts, nil
+	return &posts[0], nil
 }
 
-func (service *Service) GetPost(postID string) (*model.Post, error) {
-	post, err := service.repository.GetPost(postID)
+func (service *Service) AddPostComment(authUser model.User, postID string, commentDTO model.CommentDTO) (*model.Post, error) {
+	if len(commentDTO.UserID) != 0 {
+		if err := checkOwnership(authUser, commentDTO.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	post, err := service.getVisiblePost(authUser, postID)
 	if err != nil {
 		return nil, err
 	}
 
-	comments, err := service.repository.GetCommentsByIDList(post.CommentIDs)
+	if len(commentDTO.ParentCommentID) != 0 {
+		if _, err := service.getPostComment(postID, commentDTO.ParentCommentID); err != nil {
+			return nil, err
+		}
+	}
+
+	mentionedUsers, err := service.resolveMentions(commentDTO.Content)
 	if err != nil {
 		return nil, err
 	}
 
-	post.Comments = comments
+	comment := model.Comment{
+		ID:               utils.GenerateUUID(8),
+		UserID:           authUser.ID,
+		PostID:           postID,
+		ParentCommentID:  commentDTO.ParentCommentID,
+		User:             nil,
+		Content:          commentDTO.Content,
+		Hashtags:         utils.ExtractHashtags(commentDTO.Content),
+		MentionedUserIDs: getUserIDs(mentionedUsers),
+		CreatedAt:        time.Now().UTC().Round(time.Second),
+		UpdatedAt:        time.Now().UTC().Round(time.Second),
+	}
 
-	return post, nil
+	newComment, err := service.repository.AddComment(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.repository.AddPostCommentID(postID, newComment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyMentions(authUser, *post, newComment.ID, mentionedUsers, nil)
+
+	post, err = service.repository.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []model.Post{*post}
+	if err := service.hydratePosts(authUser, posts); err != nil {
+		return nil, err
+	}
+
+	return &posts[0], nil
 }
 
-func (service *Service) LikePost(postID string, likePostDTO model.LikePostDTO) (*model.Post, error) {
+func (service *Service) UpdatePost(authUser model.User, postID string, updatePostDTO model.UpdatePostDTO) (*model.Post, error) {
 	post, err := service.repository.GetPost(postID)
-	if err != nil {
+	if err != nil || post.DeletedAt != nil {
 		return nil, errors.PostNotFound
 	}
+	if err := checkOwnership(authUser, post.UserID); err != nil {
+		return nil, err
+	}
+	if updatePostDTO.Version != 0 && updatePostDTO.Version != post.Version {
+		return nil, errors.VersionConflict
+	}
 
-	if utils.Contains(post.WhoLikesUserIDs, likePostDTO.UserID) {
-		post.WhoLikesUserIDs = utils.RemoveElement(post.WhoLikesUserIDs, likePostDTO.UserID)
-	} else {
-		post.WhoLikesUserIDs = append(post.WhoLikesUserIDs, likePostDTO.UserID)
+	media := post.Media
+	if updatePostDTO.Image != nil || updatePostDTO.Media != nil {
+		var image string
+		if updatePostDTO.Image != nil {
+			image = *updatePostDTO.Image
+		}
+		var mediaDTOs []model.PostMediaDTO
+		if updatePostDTO.Media != nil {
+			mediaDTOs = *updatePostDTO.Media
+		}
+
+		media, err = resolvePostMedia(image, mediaDTOs)
+		if err != nil {
+			return nil, err
+		}
+		for _, postMedia := range media {
+			if postHasMedia(*post, postMedia.MediaID) {
+				continue
+			}
+			if err := service.checkMediaReference(authUser, postMedia.MediaID); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	updatedPost, err := service.repository.UpdatePost(postID, *post)
+	audience, audienceUserIDs := post.Audience, post.AudienceUserIDs
+	if len(updatePostDTO.Audience) != 0 {
+		audience, audienceUserIDs, err = resolveAudience(updatePostDTO.Audience, updatePostDTO.AudienceUserIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mentionedUsers, err := service.resolveMentions(updatePostDTO.Description)
 	if err != nil {
 		return nil, err
 	}
+	previouslyMentionedIDs := post.MentionedUserIDs
 
-	return updatedPost, nil
-}
-
-func (service *Service) AddPostComment(postID string, commentDTO model.CommentDTO) (*model.Post, error) {
-	comment := model.Comment{
-		ID:        utils.GenerateUUID(8),
-		UserID:    commentDTO.UserID,
-		PostID:    postID,
-		User:      nil,
-		Content:   commentDTO.Content,
-		CreatedAt: time.Now().UTC().Round(time.Second),
-		UpdatedAt: time.Now().UTC().Round(time.Second),
+	now := time.Now().UTC().Round(time.Second)
+	postRevision := model.PostRevision{
+		ID:              utils.GenerateUUID(8),
+		PostID:          post.ID,
+		Description:     post.Description,
+		Image:           post.Image,
+		Media:           post.Media,
+		Audience:        post.Audience,
+		AudienceUserIDs: post.AudienceUserIDs,
+		CreatedAt:       now,
 	}
 
-	newComment, err := service.repository.AddComment(comment)
+	post.Description = updatePostDTO.Description
+	post.Image = getPostImage(media)
+	post.Media = media
+	post.Hashtags = utils.ExtractHashtags(updatePostDTO.Description)
+	post.MentionedUserIDs = getUserIDs(mentionedUsers)
+	post.Audience = audience
+	post.AudienceUserIDs = audienceUserIDs
+	post.IsPrivate = audience != model.AudiencePublic
+	post.IsEdited = true
+	post.UpdatedAt = now
+
+	updatedPost, err := service.repository.UpdatePost(postID, *post)
 	if err != nil {
 		return nil, err
 	}
 
-	post, err := service.repository.GetPost(postID)
+	// The revision is saved once the edit won, so rejected edits leave no history behind.
+	err = service.repository.CreatePostRevision(postRevision)
 	if err != nil {
 		return nil, err
 	}
 
-	post.CommentIDs = append(post.CommentIDs, newComment.ID)
+	service.notifyMentions(authUser, *updatedPost, "", mentionedUsers, previouslyMentionedIDs)
 
-	_, err = service.repository.UpdatePost(postID, *post)
-	if err != nil {
+	posts := []model.Post{*updatedPost}
+	if err := service.hydratePosts(authUser, posts); err != nil {
 		return nil, err
 	}
 
-	return service.GetPost(postID)
+	return &posts[0], nil
 }
 
-func (service *Service) DeleteAdminUserPost(postID, userID string) error {
-	return service.repository.DeleteUserPost(postID, userID)
+// DeletePost hides the post until it is restored or the recovery period ends.
+func (service *Service) DeletePost(authUser model.User, postID string) error {
+	post, err := service.repository.GetPost(postID)
+	if err != nil || post.DeletedAt != nil {
+		return errors.PostNotFound
+	}
+	if err := checkOwnership(authUser, post.UserID); err != nil {
+		return err
+	}
+
+	deletedAt := time.Now().UTC().Round(time.Second)
+	post.DeletedAt = &deletedAt
+
+	_, err = service.repository.UpdatePost(postID, *post)
+
+	return err
 }
 
-func (service *Service) GetWhoLikesPost(postID string, whoLikesUserIDs []string) ([]model.User, error) {
+func (service *Service) RestorePost(authUser model.User, postID string) (*model.Post, error) {
 	post, err := service.repository.GetPost(postID)
+	if err != nil || post.DeletedAt == nil {
+		return nil, errors.PostNotFound
+	}
+	if err := checkOwnership(authUser, post.UserID); err != nil {
+		return nil, err
+	}
+	if time.Since(*post.DeletedAt) > PostRecoveryPeriod {
+		return nil, errors.PostNotFound
+	}
+
+	post.DeletedAt = nil
+
+	restoredPost, err := service.repository.UpdatePost(postID, *post)
 	if err != nil {
 		return nil, err
 	}
 
-	if !(len(post.WhoLikesUserIDs) == len(whoLikesUserIDs)) {
-		return nil, errors.WhoLikesArrayNotEqual
+	posts := []model.Post{*restoredPost}
+	if err := service.hydratePosts(authUser, posts); err != nil {
+		return nil, err
 	}
 
-	return service.repository.GetUsersByIDList(whoLikesUserIDs)
+	return &posts[0], nil
+}
+
+func (service *Service) GetPostRevisions(postID string) ([]model.PostRevision, error) {
+	_, err := service.repository.GetPost(postID)
+	if err != nil {
+		return nil, errors.PostNotFound
+	}
+
+	return service.repository.GetPostRevisions(postID)
+}
+
+// getVisiblePost returns the post when it is not deleted and its audience includes the user.
+func (service *Service) getVisiblePost(authUser model.User, postID string) (*model.Post, error) {
+	post, err := service.repository.GetPost(postID)
+	if err != nil || post.DeletedAt != nil || !canViewPost(authUser, *post) {
+		return nil, errors.PostNotFound
+	}
+
+	return post, nil
+}
+
+func postHasMedia(post model.Post, mediaID string) bool {
+	for _, postMedia := range post.Media {
+		if postMedia.MediaID == mediaID {
+			return true
+		}
+	}
+	return false
+}
+
+// canViewPost applies the same audience rules as the posts query to a single post.
+func canViewPost(viewer model.User, post model.Post) bool {
+	if post.UserID == viewer.ID {
+		return true
+	}
+	if utils.Contains(viewer.BlockedByUserIDs, post.UserID) {
+		return false
+	}
+
+	switch post.Audience {
+	case model.AudiencePublic:
+		return true
+	case model.AudienceFriends:
+		return utils.Contains(viewer.FriendIDs, post.UserID)
+	case model.AudienceCustom:
+		return utils.Contains(post.AudienceUserIDs, viewer.ID)
+	default:
+		return false
+	}
 }
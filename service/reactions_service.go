@@ -0,0 +1,172 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
+)
+
+// ReactToPost sets the user's reaction to the post, replacing any previous one.
+func (service *Service) ReactToPost(authUser model.User, postID string, reactionDTO model.ReactionDTO) (*model.Post, error) {
+	post, err := service.getVisiblePost(authUser, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.setReaction(authUser, model.ReactionTargetPost, postID, postID, reactionDTO.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []model.Post{*post}
+	if err := service.hydratePosts(authUser, posts); err != nil {
+		return nil, err
+	}
+
+	return &posts[0], nil
+}
+
+func (service *Service) RemovePostReaction(authUser model.User, postID string) error {
+	if _, err := service.getVisiblePost(authUser, postID); err != nil {
+		return err
+	}
+
+	return service.repository.DeleteReaction(model.ReactionTargetPost, postID, authUser.ID)
+}
+
+func (service *Service) GetPostReactions(authUser model.User, postID string, getReactionsQuery model.GetReactionsQuery) (*model.ReactionsCursorResponse, error) {
+	if _, err := service.getVisiblePost(authUser, postID); err != nil {
+		return nil, err
+	}
+
+	return service.getReactions(model.ReactionTargetPost, postID, getReactionsQuery)
+}
+
+// ReactToComment sets the user's reaction to the comment, replacing any previous one.
+func (service *Service) ReactToComment(authUser model.User, postID, commentID string, reactionDTO model.ReactionDTO) (*model.Comment, error) {
+	if _, err := service.getVisiblePost(authUser, postID); err != nil {
+		return nil, err
+	}
+
+	comment, err := service.getPostComment(postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.setReaction(authUser, model.ReactionTargetComment, commentID, postID, reactionDTO.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []model.Comment{*comment}
+	if err := service.hydrateComments(authUser, comments); err != nil {
+		return nil, err
+	}
+
+	return &comments[0], nil
+}
+
+func (service *Service) RemoveCommentReaction(authUser model.User, postID, commentID string) error {
+	if _, err := service.getVisiblePost(authUser, postID); err != nil {
+		return err
+	}
+
+	if _, err := service.getPostComment(postID, commentID); err != nil {
+		return err
+	}
+
+	return service.repository.DeleteReaction(model.ReactionTargetComment, commentID, authUser.ID)
+}
+
+func (service *Service) GetCommentReactions(authUser model.User, postID, commentID string, getReactionsQuery model.GetReactionsQuery) (*model.ReactionsCursorResponse, error) {
+	if _, err := service.getVisiblePost(authUser, postID); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.getPostComment(postID, commentID); err != nil {
+		return nil, err
+	}
+
+	return service.getReactions(model.ReactionTargetComment, commentID, getReactionsQuery)
+}
+
+func (service *Service) setReaction(authUser model.User, targetType, targetID, postID, reactionType string) error {
+	if !utils.Contains(model.ReactionTypes, reactionType) {
+		return errors.InvalidReactionType
+	}
+
+	reaction := model.Reaction{
+		ID:         utils.GenerateUUID(8),
+		UserID:     authUser.ID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		PostID:     postID,
+		Type:       reactionType,
+		CreatedAt:  time.Now().UTC().Round(time.Second),
+	}
+
+	return service.repository.SetReaction(reaction)
+}
+
+// getReactions returns a page of who reacted to the target, newest first.
+func (service *Service) getReactions(targetType, targetID string, getReactionsQuery model.GetReactionsQuery) (*model.ReactionsCursorResponse, error) {
+	if len(getReactionsQuery.Type) != 0 && !utils.Contains(model.ReactionTypes, getReactionsQuery.Type) {
+		return nil, errors.InvalidReactionType
+	}
+
+	reactionsCursor, err := decodeCursor(getReactionsQuery.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getReactionsQuery.Limit)
+
+	// One extra reaction tells whether there is a next page.
+	reactions, err := service.repository.GetReactions(targetType, targetID, getReactionsQuery.Type, reactionsCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ReactionsCursorResponse{Reactions: []model.ReactionView{}}
+	if len(reactions) > limit {
+		reactions = reactions[:limit]
+		lastReaction := reactions[limit-1]
+		response.NextCursor = utils.EncodeCursor(lastReaction.CreatedAt, lastReaction.ID)
+	}
+
+	var userIDs []string
+	for _, reaction := range reactions {
+		userIDs = append(userIDs, reaction.UserID)
+	}
+
+	users, err := service.repository.GetUsersByIDList(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	userViews := map[string]*model.PublicUserView{}
+	for _, user := range users {
+		userView := model.NewPublicUserView(user)
+		userViews[user.ID] = &userView
+	}
+
+	for _, reaction := range reactions {
+		response.Reactions = append(response.Reactions, model.ReactionView{
+			User:      userViews[reaction.UserID],
+			Type:      reaction.Type,
+			CreatedAt: reaction.CreatedAt,
+		})
+	}
+
+	return &response, nil
+}
+
+// getReactionCounts returns a count for every reaction type, so clients always get the same keys.
+func getReactionCounts(counts map[string]int) map[string]int {
+	reactionCounts := map[string]int{}
+	for _, reactionType := range model.ReactionTypes {
+		reactionCounts[reactionType] = counts[reactionType]
+	}
+
+	return reactionCounts
+}
@@ -1,7 +1,15 @@
 package service
 
 import (
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
 	"github.com/anilaydinn/socium-be/repository"
+	"github.com/anilaydinn/socium-be/utils"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
 )
 
 type Service struct {
@@ -13,3 +21,35 @@ func NewService(repository *repository.Repository) Service {
 		repository: repository,
 	}
 }
+
+// checkOwnership rejects requests where the authenticated user acts on another user's resources.
+func checkOwnership(authUser model.User, userID string) error {
+	if authUser.ID != userID {
+		return errors.Forbidden
+	}
+	return nil
+}
+
+// decodeCursor returns nil for an empty cursor, which starts from the first page.
+func decodeCursor(cursor string) (*model.Cursor, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+
+	createdAt, id, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, errors.InvalidCursor
+	}
+
+	return &model.Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+func getPageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
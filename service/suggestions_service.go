@@ -0,0 +1,257 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// suggestionsPerUser is how many suggestions are stored for each user, more than a page
+	// so that some are left once friends and requests made since are taken out.
+	suggestionsPerUser = 100
+	// suggestionRadiusKM is the distance within which users are considered close.
+	suggestionRadiusKM = 50
+	// suggestionHashtagPeriod is how far back the hashtags users posted are compared.
+	suggestionHashtagPeriod = 30 * 24 * time.Hour
+	// suggestionInteractionPeriod is how far back the reactions and comments users left are counted.
+	suggestionInteractionPeriod = 30 * 24 * time.Hour
+	// suggestionsCacheTTL is how long stored suggestions are served without reading them again.
+	suggestionsCacheTTL = 10 * time.Minute
+
+	mutualFriendWeight  = 3
+	sharedHashtagWeight = 1
+	interactionWeight   = 2
+	closenessWeight     = 2
+)
+
+type suggestionsCache struct {
+	sync.RWMutex
+	entries map[string]suggestionsCacheEntry
+}
+
+type suggestionsCacheEntry struct {
+	suggestions []model.Suggestion
+	expiresAt   time.Time
+}
+
+var cachedSuggestions = suggestionsCache{
+	entries: map[string]suggestionsCacheEntry{},
+}
+
+func (c *suggestionsCache) get(userID string) ([]model.Suggestion, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	entry, ok := c.entries[userID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return entry.suggestions, true
+}
+
+func (c *suggestionsCache) set(userID string, suggestions []model.Suggestion) {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	for id, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+
+	c.entries[userID] = suggestionsCacheEntry{
+		suggestions: suggestions,
+		expiresAt:   now.Add(suggestionsCacheTTL),
+	}
+}
+
+func (c *suggestionsCache) clear() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries = map[string]suggestionsCacheEntry{}
+}
+
+// GetUserSuggestions returns the users the user may know, best first. The suggestions are
+// precomputed by RefreshSuggestions, friendships, friend requests and blocks made since are
+// taken out here.
+func (service *Service) GetUserSuggestions(authUser model.User, userID string, getSuggestionsQuery model.GetSuggestionsQuery) ([]model.Suggestion, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+	limit := getPageLimit(getSuggestionsQuery.Limit)
+
+	suggestions, ok := cachedSuggestions.get(userID)
+	if !ok {
+		var err error
+		suggestions, err = service.repository.GetSuggestions(userID)
+		if err != nil {
+			return nil, err
+		}
+		cachedSuggestions.set(userID, suggestions)
+	}
+
+	excludedUserIDs, err := service.getBlockedUserIDs(authUser)
+	if err != nil {
+		return nil, err
+	}
+	excludedUserIDs = append(excludedUserIDs, authUser.FriendIDs...)
+	friendRequests, err := service.repository.GetUserFriendshipsWithStatus(userID, []string{model.FriendshipPending})
+	if err != nil {
+		return nil, err
+	}
+	for _, friendRequest := range friendRequests {
+		excludedUserIDs = append(excludedUserIDs, friendRequest.RequesterID, friendRequest.AddresseeID)
+	}
+
+	var suggestedUserIDs []string
+	for _, suggestion := range suggestions {
+		if !utils.Contains(excludedUserIDs, suggestion.UserID) {
+			suggestedUserIDs = append(suggestedUserIDs, suggestion.UserID)
+		}
+	}
+	users, err := service.repository.GetUsersByIDList(suggestedUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	userViews := map[string]*model.PublicUserView{}
+	for _, user := range users {
+		userView := model.NewPublicUserView(user)
+		userViews[user.ID] = &userView
+	}
+
+	userSuggestions := []model.Suggestion{}
+	for _, suggestion := range suggestions {
+		if len(userSuggestions) == limit {
+			break
+		}
+		if userViews[suggestion.UserID] == nil {
+			continue
+		}
+		suggestion.User = userViews[suggestion.UserID]
+		userSuggestions = append(userSuggestions, suggestion)
+	}
+
+	return userSuggestions, nil
+}
+
+// RefreshSuggestions computes and stores the suggestions of every user and returns the number
+// of users. Candidates are friends of friends, users who recently posted the same hashtags,
+// users who recently reacted to or commented on each other's posts and users living close by,
+// leaving out friends, users with a pending friend request or a block in either direction and
+// users who cannot log in. It is run by the maintenance command.
+func (service *Service) RefreshSuggestions() (int, error) {
+	users, err := service.repository.GetSuggestionUsers()
+	if err != nil {
+		return 0, err
+	}
+
+	friendships, err := service.repository.GetFriendshipsWithStatus([]string{model.FriendshipPending, model.FriendshipBlocked})
+	if err != nil {
+		return 0, err
+	}
+	excludedUserIDs := map[string][]string{}
+	for _, friendship := range friendships {
+		excludedUserIDs[friendship.RequesterID] = append(excludedUserIDs[friendship.RequesterID], friendship.AddresseeID)
+		excludedUserIDs[friendship.AddresseeID] = append(excludedUserIDs[friendship.AddresseeID], friendship.RequesterID)
+	}
+
+	hashtagsByUserID, err := service.repository.GetRecentHashtagsByUser(time.Now().Add(-suggestionHashtagPeriod))
+	if err != nil {
+		return 0, err
+	}
+	userIDsByHashtag := map[string][]string{}
+	for userID, hashtags := range hashtagsByUserID {
+		for _, hashtag := range hashtags {
+			userIDsByHashtag[hashtag] = append(userIDsByHashtag[hashtag], userID)
+		}
+	}
+
+	interactionCounts, err := service.repository.GetRecentInteractionCounts(time.Now().Add(-suggestionInteractionPeriod))
+	if err != nil {
+		return 0, err
+	}
+
+	usersByID := map[string]model.User{}
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	suggestionsByUserID := map[string][]model.Suggestion{}
+	for _, user := range users {
+		candidates := map[string]*model.Suggestion{}
+		getCandidate := func(candidateID string) *model.Suggestion {
+			if candidates[candidateID] == nil {
+				candidates[candidateID] = &model.Suggestion{UserID: candidateID}
+			}
+			return candidates[candidateID]
+		}
+
+		for _, friendID := range user.FriendIDs {
+			for _, candidateID := range usersByID[friendID].FriendIDs {
+				getCandidate(candidateID).MutualFriendCount++
+			}
+		}
+		for _, hashtag := range hashtagsByUserID[user.ID] {
+			for _, candidateID := range userIDsByHashtag[hashtag] {
+				getCandidate(candidateID).SharedHashtagCount++
+			}
+		}
+		for candidateID, interactionCount := range interactionCounts[user.ID] {
+			getCandidate(candidateID).InteractionCount += interactionCount
+		}
+		closeness := map[string]float64{}
+		if hasLocation(user) {
+			distances, err := service.repository.GetNearbyUserDistances(user.ID, user.Latitude, user.Longitude, suggestionRadiusKM, suggestionsPerUser)
+			if err != nil {
+				return 0, err
+			}
+			for candidateID, distance := range distances {
+				getCandidate(candidateID).IsNearby = true
+				closeness[candidateID] = 1 - distance/suggestionRadiusKM
+			}
+		}
+
+		suggestions := []model.Suggestion{}
+		for candidateID, candidate := range candidates {
+			// Only users who can log in are loaded.
+			if _, ok := usersByID[candidateID]; !ok || candidateID == user.ID ||
+				utils.Contains(user.FriendIDs, candidateID) || utils.Contains(excludedUserIDs[user.ID], candidateID) {
+				continue
+			}
+			candidate.Score = mutualFriendWeight*float64(candidate.MutualFriendCount) +
+				sharedHashtagWeight*float64(candidate.SharedHashtagCount) +
+				interactionWeight*float64(candidate.InteractionCount) +
+				closenessWeight*closeness[candidateID]
+			suggestions = append(suggestions, *candidate)
+		}
+
+		sort.Slice(suggestions, func(i, j int) bool {
+			if suggestions[i].Score != suggestions[j].Score {
+				return suggestions[i].Score > suggestions[j].Score
+			}
+			return suggestions[i].UserID < suggestions[j].UserID
+		})
+		if len(suggestions) > suggestionsPerUser {
+			suggestions = suggestions[:suggestionsPerUser]
+		}
+		suggestionsByUserID[user.ID] = suggestions
+	}
+
+	if err := service.repository.ReplaceSuggestions(suggestionsByUserID, time.Now().UTC().Round(time.Second)); err != nil {
+		return 0, err
+	}
+	cachedSuggestions.clear()
+
+	return len(suggestionsByUserID), nil
+}
+
+// hasLocation tells whether the user shared their coordinates, which are zero otherwise.
+func hasLocation(user model.User) bool {
+	return user.Latitude != 0 || user.Longitude != 0
+}
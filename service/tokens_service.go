@@ -0,0 +1,165 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"time"
+)
+
+func (service *Service) RefreshToken(refreshTokenDTO model.RefreshTokenDTO) (*model.Token, error) {
+	if len(refreshTokenDTO.RefreshToken) == 0 {
+		return nil, errors.InvalidRefreshToken
+	}
+
+	refreshToken, err := service.repository.GetRefreshTokenByHash(utils.HashToken(refreshTokenDTO.RefreshToken))
+	if err != nil {
+		return nil, errors.InvalidRefreshToken
+	}
+
+	if refreshToken.IsRevoked || time.Now().UTC().After(refreshToken.ExpiresAt) {
+		return nil, errors.InvalidRefreshToken
+	}
+
+	user, err := service.repository.GetUser(refreshToken.UserID)
+	if err != nil {
+		return nil, errors.UserNotFound
+	}
+
+	if !user.IsActivated {
+		return nil, errors.Unauthorized
+	}
+
+	// Refresh tokens are single use, a new one is issued with every access token.
+	err = service.repository.RevokeRefreshToken(refreshToken.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.generateUserToken(*user)
+}
+
+func (service *Service) Logout(claims model.CustomClaims, refreshTokenDTO model.RefreshTokenDTO) error {
+	expiresAt := time.Unix(claims.ExpiresAt, 0).UTC()
+
+	err := service.repository.RevokeToken(model.RevokedToken{
+		TokenID:   claims.Id,
+		UserID:    claims.Issuer,
+		RevokedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	auth.CacheRevokedToken(claims.Id, claims.Issuer, expiresAt)
+
+	if len(refreshTokenDTO.RefreshToken) == 0 {
+		return nil
+	}
+
+	refreshToken, err := service.repository.GetRefreshTokenByHash(utils.HashToken(refreshTokenDTO.RefreshToken))
+	if err != nil || refreshToken.UserID != claims.Issuer || refreshToken.IsRevoked {
+		return nil
+	}
+
+	return service.repository.RevokeRefreshToken(refreshToken.ID)
+}
+
+func (service *Service) LogoutAll(userID string) error {
+	err := service.repository.IncrementTokenVersion(userID)
+	if err != nil {
+		return err
+	}
+	auth.CacheRevokedUserTokens(userID)
+
+	return service.repository.RevokeUserRefreshTokens(userID)
+}
+
+func (service *Service) generateUserToken(user model.User) (*model.Token, error) {
+	accessToken, claims, err := auth.GenerateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRefreshToken := utils.GenerateSecureToken(32)
+	refreshToken := model.RefreshToken{
+		ID:        utils.GenerateUUID(8),
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawRefreshToken),
+		IsRevoked: false,
+		ExpiresAt: time.Now().UTC().Add(utils.GetRefreshTokenTTL()),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	_, err = service.repository.CreateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Token{
+		Token:        accessToken,
+		RefreshToken: rawRefreshToken,
+		ExpiresAt:    time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+// createVerificationToken stores a new single-use token for the user and returns the raw
+// value to be emailed. Older unused tokens with the same purpose stop working.
+func (service *Service) createVerificationToken(userID, purpose string, ttl time.Duration) (string, error) {
+	err := service.repository.InvalidateUserVerificationTokens(userID, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	rawToken := utils.GenerateSecureToken(32)
+	verificationToken := model.VerificationToken{
+		ID:        utils.GenerateUUID(8),
+		UserID:    userID,
+		TokenHash: utils.HashToken(rawToken),
+		Purpose:   purpose,
+		IsUsed:    false,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	_, err = service.repository.CreateVerificationToken(verificationToken)
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// peekVerificationToken checks the raw token without using it up.
+func (service *Service) peekVerificationToken(rawToken, purpose string) (*model.VerificationToken, error) {
+	if len(rawToken) == 0 {
+		return nil, errors.InvalidVerificationToken
+	}
+
+	verificationToken, err := service.repository.GetVerificationTokenByHash(utils.HashToken(rawToken), purpose)
+	if err != nil {
+		return nil, errors.InvalidVerificationToken
+	}
+
+	if verificationToken.IsUsed || time.Now().UTC().After(verificationToken.ExpiresAt) {
+		return nil, errors.InvalidVerificationToken
+	}
+
+	return verificationToken, nil
+}
+
+// useVerificationToken checks the raw token and marks it used, returning the user it was issued for.
+func (service *Service) useVerificationToken(rawToken, purpose string) (string, error) {
+	verificationToken, err := service.peekVerificationToken(rawToken, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	err = service.repository.UseVerificationToken(verificationToken.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return verificationToken.UserID, nil
+}
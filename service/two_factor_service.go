@@ -0,0 +1,174 @@
+package service
+
+import (
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+	"time"
+)
+
+const (
+	twoFactorIssuer       = "Socium"
+	twoFactorChallengeTTL = 5 * time.Minute
+	recoveryCodeCount     = 10
+)
+
+func (service *Service) EnrollTwoFactor(authUser model.User) (*model.TwoFactorEnrollment, error) {
+	user, err := service.repository.GetUser(authUser.ID)
+	if err != nil {
+		return nil, errors.UserNotFound
+	}
+
+	if user.IsTwoFactorEnabled {
+		return nil, errors.TwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// The secret stays pending until the user proves their authenticator works.
+	user.TwoFactorSecret = secret
+	user.TwoFactorLastStep = 0
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	_, err = service.repository.UpdateUser(user.ID, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: auth.GetTOTPURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+func (service *Service) VerifyTwoFactor(authUser model.User, twoFactorCodeDTO model.TwoFactorCodeDTO) (*model.RecoveryCodes, error) {
+	user, err := service.repository.GetUser(authUser.ID)
+	if err != nil {
+		return nil, errors.UserNotFound
+	}
+
+	if user.IsTwoFactorEnabled {
+		return nil, errors.TwoFactorAlreadyEnabled
+	}
+
+	if len(user.TwoFactorSecret) == 0 {
+		return nil, errors.TwoFactorNotEnabled
+	}
+
+	step, ok := auth.ValidateTOTPCode(user.TwoFactorSecret, twoFactorCodeDTO.Code, time.Now())
+	if !ok {
+		return nil, errors.InvalidTwoFactorCode
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		code := utils.GenerateSecureToken(5)
+		recoveryCodes[i] = code[:5] + "-" + code[5:]
+		recoveryCodeHashes[i] = utils.HashToken(code)
+	}
+
+	user.IsTwoFactorEnabled = true
+	user.TwoFactorLastStep = step
+	user.RecoveryCodeHashes = recoveryCodeHashes
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	_, err = service.repository.UpdateUser(user.ID, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodes{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (service *Service) DisableTwoFactor(authUser model.User, twoFactorCodeDTO model.TwoFactorCodeDTO) error {
+	user, err := service.repository.GetUser(authUser.ID)
+	if err != nil {
+		return errors.UserNotFound
+	}
+
+	if !user.IsTwoFactorEnabled {
+		return errors.TwoFactorNotEnabled
+	}
+
+	if err := checkTwoFactorCode(user, twoFactorCodeDTO.Code, twoFactorCodeDTO.RecoveryCode); err != nil {
+		return err
+	}
+
+	user.IsTwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	user.TwoFactorLastStep = 0
+	user.RecoveryCodeHashes = nil
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	_, err = service.repository.UpdateUser(user.ID, *user)
+	return err
+}
+
+// LoginTwoFactor exchanges the challenge token returned by LoginUser and a valid code for the
+// user's tokens. A challenge can only be tried once, a wrong code means logging in again.
+func (service *Service) LoginTwoFactor(twoFactorLoginDTO model.TwoFactorLoginDTO) (*model.Token, *fiber.Cookie, error) {
+	userID, err := service.useVerificationToken(twoFactorLoginDTO.ChallengeToken, model.TwoFactorChallenge)
+	if err != nil {
+		return nil, nil, errors.Unauthorized
+	}
+
+	user, err := service.repository.GetUser(userID)
+	if err != nil {
+		return nil, nil, errors.UserNotFound
+	}
+
+	if user.IsBanned {
+		return nil, nil, errors.UserBanned
+	}
+
+	if !user.IsTwoFactorEnabled {
+		return nil, nil, errors.Unauthorized
+	}
+
+	if err := checkTwoFactorCode(user, twoFactorLoginDTO.Code, twoFactorLoginDTO.RecoveryCode); err != nil {
+		return nil, nil, err
+	}
+
+	_, err = service.repository.UpdateUser(user.ID, *user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return service.completeLogin(*user, true)
+}
+
+// checkTwoFactorCode accepts either a TOTP code or an unused recovery code. The user is updated
+// so the same code cannot be used again; callers are responsible for saving it.
+func checkTwoFactorCode(user *model.User, code, recoveryCode string) error {
+	if len(code) > 0 {
+		step, ok := auth.ValidateTOTPCode(user.TwoFactorSecret, code, time.Now())
+		if !ok || step <= user.TwoFactorLastStep {
+			return errors.InvalidTwoFactorCode
+		}
+		user.TwoFactorLastStep = step
+		return nil
+	}
+
+	if len(recoveryCode) > 0 {
+		recoveryCodeHash := utils.HashToken(normalizeRecoveryCode(recoveryCode))
+		for i, hash := range user.RecoveryCodeHashes {
+			if hash == recoveryCodeHash {
+				user.RecoveryCodeHashes = append(user.RecoveryCodeHashes[:i], user.RecoveryCodeHashes[i+1:]...)
+				return nil
+			}
+		}
+	}
+
+	return errors.InvalidTwoFactorCode
+}
+
+func normalizeRecoveryCode(recoveryCode string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(recoveryCode), "-", ""))
+}
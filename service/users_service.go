@@ -1,26 +1,37 @@
 package service
 
 import (
+	"github.com/anilaydinn/socium-be/auth"
 	"github.com/anilaydinn/socium-be/email"
 	"github.com/anilaydinn/socium-be/errors"
 	"github.com/anilaydinn/socium-be/model"
 	"github.com/anilaydinn/socium-be/utils"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
 	"math"
 	"os"
+	"strings"
 	"time"
 )
 
+const (
+	activationTokenTTL    = 24 * time.Hour
+	passwordResetTokenTTL = time.Hour
+	// A forced password change is asked for after login, so the challenge can live a bit longer than the 2FA one.
+	passwordChangeChallengeTTL = 15 * time.Minute
+	// nearUsersRadiusKM is the distance within which users are returned as near.
+	nearUsersRadiusKM = 20
+	// nearUsersLimit is how many of the closest users are returned as near.
+	nearUsersLimit = 50
+)
+
 func (service *Service) RegisterUser(userDTO model.UserDTO) (*model.User, error) {
 	alreadyRegisteredUser, err := service.repository.GetUserByEmail(userDTO.Email)
 	if alreadyRegisteredUser != nil {
 		return nil, errors.UserAlreadyRegistered
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userDTO.Password), bcrypt.DefaultCost)
-
+	username, err := service.generateUsername(userDTO.Name, userDTO.Surname)
 	if err != nil {
 		return nil, err
 	}
@@ -29,10 +40,10 @@ func (service *Service) RegisterUser(userDTO model.UserDTO) (*model.User, error)
 		ID:          utils.GenerateUUID(8),
 		Name:        userDTO.Name,
 		Surname:     userDTO.Surname,
+		Username:    username,
 		Email:       userDTO.Email,
 		BirthDate:   userDTO.BirthDate,
-		Password:    string(hashedPassword),
-		UserType:    "user",
+		UserType:    model.RoleUser,
 		IsActivated: false,
 		CreatedAt:   time.Now().UTC().Round(time.Minute),
 		UpdatedAt:   time.Now().UTC().Round(time.Minute),
@@ -40,13 +51,22 @@ func (service *Service) RegisterUser(userDTO model.UserDTO) (*model.User, error)
 		Longitude:   userDTO.Longitude,
 	}
 
+	if err := setPassword(&user, userDTO.Password); err != nil {
+		return nil, err
+	}
+
 	newUser, err := service.repository.RegisterUser(user)
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = email.SendMail(newUser.Email, "Complete Registration", "Please click "+os.Getenv("REACT_HOSTNAME")+"/activation/"+user.ID)
+	activationToken, err := service.createVerificationToken(newUser.ID, model.ActivationToken, activationTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = email.SendMail(newUser.Email, "Complete Registration", "Please click "+os.Getenv("REACT_HOSTNAME")+"/activation/"+activationToken)
 	if err != nil {
 		return nil, err
 	}
@@ -54,9 +74,22 @@ func (service *Service) RegisterUser(userDTO model.UserDTO) (*model.User, error)
 	return newUser, nil
 }
 
-func (service *Service) LoginUser(userCredentialsDTO model.UserCredentialsDTO) (*model.Token, *fiber.Cookie, error) {
+func (service *Service) LoginUser(userCredentialsDTO model.UserCredentialsDTO, clientIP string) (*model.Token, *fiber.Cookie, error) {
+	accountKey := accountLoginKey(userCredentialsDTO.Email)
+	ipKey := ipLoginKey(clientIP)
+
+	if err := service.checkAttemptLocks(accountKey, ipKey); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := service.repository.GetUserByEmail(userCredentialsDTO.Email)
 
+	if err == errors.UserNotFound {
+		if err := service.recordFailedAttempt(ipKey, ipLoginAttemptLimit, "", clientIP); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if err != nil {
 		return nil, nil, err
 	}
@@ -69,34 +102,71 @@ func (service *Service) LoginUser(userCredentialsDTO model.UserCredentialsDTO) (
 		return nil, nil, errors.Unauthorized
 	}
 
+	if user.IsBanned {
+		return nil, nil, errors.UserBanned
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userCredentialsDTO.Password)); err != nil {
+		if err := service.recordFailedAttempt(accountKey, accountLoginAttemptLimit, user.ID, clientIP); err != nil {
+			return nil, nil, err
+		}
+		if err := service.recordFailedAttempt(ipKey, ipLoginAttemptLimit, user.ID, clientIP); err != nil {
+			return nil, nil, err
+		}
 		return nil, nil, errors.WrongPassword
 	}
 
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, model.CustomClaims{
-		UserType: user.UserType,
-		StandardClaims: jwt.StandardClaims{
-			Issuer: user.ID,
-		},
-	})
+	// Only the account is reset, a valid login must not clear failures of other accounts from the same address.
+	if err := service.resetAttempts(accountKey, user.ID, clientIP); err != nil {
+		return nil, nil, err
+	}
+
+	return service.completeLogin(*user, false)
+}
+
+// completeLogin issues the user's tokens once the password is checked. A challenge token is
+// returned instead while the second factor or a forced password change is still pending.
+func (service *Service) completeLogin(user model.User, isTwoFactorVerified bool) (*model.Token, *fiber.Cookie, error) {
+	if user.IsTwoFactorEnabled && !isTwoFactorVerified {
+		challengeToken, err := service.createVerificationToken(user.ID, model.TwoFactorChallenge, twoFactorChallengeTTL)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &model.Token{TwoFactorRequired: true, ChallengeToken: challengeToken}, nil, nil
+	}
+
+	if user.MustChangePassword {
+		challengeToken, err := service.createVerificationToken(user.ID, model.PasswordChangeChallenge, passwordChangeChallengeTTL)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	token, err := claims.SignedString([]byte(""))
+		return &model.Token{PasswordChangeRequired: true, ChallengeToken: challengeToken}, nil, nil
+	}
 
+	token, err := service.generateUserToken(user)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	cookie := fiber.Cookie{
+	return token, newUserTokenCookie(*token), nil
+}
+
+func newUserTokenCookie(token model.Token) *fiber.Cookie {
+	return &fiber.Cookie{
 		Name:    "user-token",
-		Value:   token,
-		Expires: time.Now().Add(time.Hour * 24),
+		Value:   token.Token,
+		Expires: token.ExpiresAt,
 	}
-	return &model.Token{
-		Token: token,
-	}, &cookie, nil
 }
 
-func (service *Service) Activation(userID string) (*model.User, error) {
+func (service *Service) Activation(token string) (*model.User, error) {
+	userID, err := service.useVerificationToken(token, model.ActivationToken)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := service.repository.GetUser(userID)
 	if err != nil {
 		return nil, err
@@ -115,7 +185,22 @@ func (service *Service) Activation(userID string) (*model.User, error) {
 	return service.repository.UpdateUser(userID, *user)
 }
 
-func (service *Service) ForgotPassword(forgotPasswordDTO model.ForgotPasswordDTO) error {
+func (service *Service) ForgotPassword(forgotPasswordDTO model.ForgotPasswordDTO, clientIP string) error {
+	accountKey := accountForgotPasswordKey(forgotPasswordDTO.Email)
+	ipKey := ipForgotPasswordKey(clientIP)
+
+	if err := service.checkAttemptLocks(accountKey, ipKey); err != nil {
+		return err
+	}
+
+	// Every request counts, so reset emails cannot be used to flood an inbox.
+	if err := service.recordFailedAttempt(accountKey, accountForgotPasswordLimit, "", clientIP); err != nil {
+		return err
+	}
+	if err := service.recordFailedAttempt(ipKey, ipForgotPasswordLimit, "", clientIP); err != nil {
+		return err
+	}
+
 	registeredUser, _ := service.repository.GetUserByEmail(forgotPasswordDTO.Email)
 	if registeredUser == nil {
 		return errors.UserNotFound
@@ -125,7 +210,12 @@ func (service *Service) ForgotPassword(forgotPasswordDTO model.ForgotPasswordDTO
 		return errors.UserNotActivated
 	}
 
-	err := email.SendMail(forgotPasswordDTO.Email, "Reset Password", "You can reset your password click "+os.Getenv("REACT_HOSTNAME")+"/reset-password/"+registeredUser.ID+" here.")
+	resetToken, err := service.createVerificationToken(registeredUser.ID, model.PasswordResetToken, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	err = email.SendMail(forgotPasswordDTO.Email, "Reset Password", "You can reset your password click "+os.Getenv("REACT_HOSTNAME")+"/reset-password/"+resetToken+" here.")
 	if err != nil {
 		return err
 	}
@@ -133,129 +223,209 @@ func (service *Service) ForgotPassword(forgotPasswordDTO model.ForgotPasswordDTO
 	return nil
 }
 
-func (service *Service) ResetPassword(userID string, resetPasswordDTO model.ResetPasswordDTO) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(resetPasswordDTO.Password), bcrypt.DefaultCost)
+func (service *Service) ResetPassword(token string, resetPasswordDTO model.ResetPasswordDTO) error {
+	verificationToken, err := service.peekVerificationToken(token, model.PasswordResetToken)
 	if err != nil {
 		return err
 	}
 
-	user, err := service.repository.GetUser(userID)
+	user, err := service.repository.GetUser(verificationToken.UserID)
 	if err != nil {
 		return errors.UserNotFound
 	}
 
-	user.Password = string(hashedPassword)
+	// The token is only used up once the new password is accepted, so a rejected password can be retried.
+	if err := setPassword(user, resetPasswordDTO.Password); err != nil {
+		return err
+	}
+
+	userID, err := service.useVerificationToken(token, model.PasswordResetToken)
+	if err != nil {
+		return err
+	}
 
 	_, err = service.repository.UpdateUser(userID, *user)
 	if err != nil {
 		return err
 	}
-	return nil
-}
 
-func (service *Service) GetUser(userID string) (*model.User, error) {
-	return service.repository.GetUser(userID)
+	// Sessions opened with the old password should not outlive the reset.
+	return service.LogoutAll(userID)
 }
 
-func (service *Service) UpdateUser(userID string, updateUserDTO model.UpdateUserDTO) (*model.User, error) {
-	user, err := service.repository.GetUser(userID)
+// ChangeRequiredPassword sets the new password of a user an admin forced to change it,
+// using the challenge token returned by the login, and completes the login.
+func (service *Service) ChangeRequiredPassword(passwordChangeDTO model.PasswordChangeDTO) (*model.Token, *fiber.Cookie, error) {
+	verificationToken, err := service.peekVerificationToken(passwordChangeDTO.ChallengeToken, model.PasswordChangeChallenge)
 	if err != nil {
-		return nil, errors.UserNotFound
+		return nil, nil, errors.Unauthorized
 	}
-	user.Description = updateUserDTO.Description
-	user.ProfileImage = updateUserDTO.ProfileImage
 
-	updatedUser, err := service.repository.UpdateUser(userID, *user)
+	user, err := service.repository.GetUser(verificationToken.UserID)
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.UserNotFound
 	}
 
-	return updatedUser, nil
-}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(passwordChangeDTO.Password)) == nil {
+		return nil, nil, &errors.ValidationErrors{Errors: []errors.ValidationError{{
+			Field:   "password",
+			Code:    "reused",
+			Message: "New password must be different from the current one.",
+		}}}
+	}
 
-func (service *Service) SendFriendRequest(targetUserID string, friendRequestDTO model.FriendRequestDTO) (*model.User, error) {
-	user, err := service.GetUser(targetUserID)
-	if err != nil {
-		return nil, errors.UserNotFound
+	if err := setPassword(user, passwordChangeDTO.Password); err != nil {
+		return nil, nil, err
 	}
-	if !utils.Contains(user.FriendRequestUserIDs, friendRequestDTO.UserID) {
-		user.FriendRequestUserIDs = append(user.FriendRequestUserIDs, friendRequestDTO.UserID)
+
+	_, err = service.useVerificationToken(passwordChangeDTO.ChallengeToken, model.PasswordChangeChallenge)
+	if err != nil {
+		return nil, nil, errors.Unauthorized
 	}
 
-	updatedUser, err := service.repository.UpdateUser(targetUserID, *user)
+	user, err = service.repository.UpdateUser(user.ID, *user)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return updatedUser, nil
+	return service.completeLogin(*user, true)
 }
 
-func (service *Service) GetUserFriendRequests(userID string) ([]model.User, error) {
+func (service *Service) AdminForcePasswordChange(authUser model.User, userID string) error {
+	if authUser.ID == userID {
+		return errors.Forbidden
+	}
+
 	user, err := service.repository.GetUser(userID)
 	if err != nil {
-		return nil, errors.UserNotFound
+		return errors.UserNotFound
 	}
 
-	friendRequestUsers, err := service.repository.GetUsersByIDList(user.FriendRequestUserIDs)
+	user.MustChangePassword = true
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	_, err = service.repository.UpdateUser(userID, *user)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return friendRequestUsers, nil
+	// Open sessions end so the change is asked for right away.
+	return service.LogoutAll(userID)
 }
 
-func (service *Service) AcceptOrDeclineUserFriendRequest(userID, targetID string, acceptOrDeclineFriendRequestDTO model.AcceptOrDeclineFriendRequestDTO) (*model.User, error) {
-	user, err := service.repository.GetUser(userID)
+// setPassword checks the password against the policy and stores its hash on the user.
+func setPassword(user *model.User, password string) error {
+	if validationErrors := auth.GetPasswordPolicy().Validate(password, *user); validationErrors != nil {
+		return validationErrors
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, errors.UserNotFound
+		return err
 	}
 
-	targetUser, err := service.repository.GetUser(targetID)
+	user.Password = string(hashedPassword)
+	user.MustChangePassword = false
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	return nil
+}
+
+func (service *Service) GetUser(userID string) (*model.User, error) {
+	return service.repository.GetUser(userID)
+}
+
+func (service *Service) UpdateUser(authUser model.User, userID string, updateUserDTO model.UpdateUserDTO) (*model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := service.repository.GetUser(userID)
 	if err != nil {
 		return nil, errors.UserNotFound
 	}
-
-	if acceptOrDeclineFriendRequestDTO.Accept {
-		user.FriendIDs = append(user.FriendIDs, targetID)
-		targetUser.FriendIDs = append(targetUser.FriendIDs, userID)
-		user.FriendRequestUserIDs = utils.RemoveElement(user.FriendRequestUserIDs, targetID)
-	} else {
-		user.FriendRequestUserIDs = utils.RemoveElement(user.FriendRequestUserIDs, targetID)
+	if updateUserDTO.Version != 0 && updateUserDTO.Version != user.Version {
+		return nil, errors.VersionConflict
+	}
+	if updateUserDTO.ProfileImage != user.ProfileImage {
+		if err := service.checkMediaReference(authUser, updateUserDTO.ProfileImage); err != nil {
+			return nil, err
+		}
+	}
+	if username := strings.ToLower(updateUserDTO.Username); len(username) != 0 && username != user.Username {
+		if !utils.IsValidUsername(username) {
+			return nil, errors.InvalidUsername
+		}
+		if takenBy, _ := service.repository.GetUserByUsername(username); takenBy != nil {
+			return nil, errors.UsernameTaken
+		}
+		user.Username = username
 	}
+	user.Description = updateUserDTO.Description
+	user.ProfileImage = updateUserDTO.ProfileImage
 
 	updatedUser, err := service.repository.UpdateUser(userID, *user)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = service.repository.UpdateUser(targetID, *targetUser)
+	return updatedUser, nil
+}
+
+// GetUserProfile returns the user with their friend and follow counts unless they blocked
+// the viewer.
+func (service *Service) GetUserProfile(authUser model.User, userID string) (*model.UserProfile, error) {
+	user, err := service.getVisibleUser(authUser, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return updatedUser, nil
-}
+	mutualFriendCount, err := service.repository.CountMutualFriends(authUser.FriendIDs, userID)
+	if err != nil {
+		return nil, err
+	}
 
-func (service *Service) GetUserFriends(userID string) ([]model.User, error) {
-	user, err := service.repository.GetUser(userID)
+	followerCount, followingCount, err := service.repository.CountFollows(userID)
 	if err != nil {
-		return nil, errors.UserNotFound
+		return nil, err
 	}
 
-	friends, err := service.repository.GetUsersByIDList(user.FriendIDs)
+	isFollowing, err := service.repository.IsFollowing(authUser.ID, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return friends, nil
+	return &model.UserProfile{
+		User:              *user,
+		FriendCount:       len(user.FriendIDs),
+		MutualFriendCount: mutualFriendCount,
+		FollowerCount:     followerCount,
+		FollowingCount:    followingCount,
+		IsFollowing:       isFollowing,
+	}, nil
 }
 
-func (service *Service) GetUsersWithFilter(filterArr []string) ([]model.User, error) {
+// GetUsersWithFilter returns the users matching the name filter, leaving out users the viewer
+// blocked or was blocked by.
+func (service *Service) GetUsersWithFilter(authUser model.User, filterArr []string) ([]model.User, error) {
 	users, err := service.repository.GetUsersWithFilter(filterArr)
 	if err != nil {
 		return nil, err
 	}
-	return users, nil
+
+	blockedUserIDs, err := service.getBlockedUserIDs(authUser)
+	if err != nil {
+		return nil, err
+	}
+
+	var visibleUsers []model.User
+	for _, user := range users {
+		if !utils.Contains(blockedUserIDs, user.ID) {
+			visibleUsers = append(visibleUsers, user)
+		}
+	}
+
+	return visibleUsers, nil
 }
 
 func (service *Service) GetAllUsers(pageNumber, size int, filterArr []string) (*model.UsersPageableResponse, error) {
@@ -291,53 +461,129 @@ func (service *Service) GetUserPosts(userID string) ([]model.Post, error) {
 		return nil, err
 	}
 
+	userView := model.NewPublicUserView(*user)
+
 	var postResults []model.Post
 	for _, post := range posts {
-		post.User = user
+		post.User = &userView
 		postResults = append(postResults, post)
 	}
 
 	return postResults, nil
 }
 
-func (service *Service) GetNearUsers(userID string, getNearUsersDTO model.GetNearUsersDTO) ([]model.User, error) {
-	users, _, err := service.repository.GetAllUsers(0, 0, []string{})
-	if err != nil {
+func (service *Service) GetNearUsers(authUser model.User, userID string, getNearUsersDTO model.GetNearUsersDTO) ([]model.User, error) {
+	if err := checkOwnership(authUser, userID); err != nil {
 		return nil, err
 	}
 
-	nearUsers := []model.User{}
-	for _, user := range users {
-		if utils.CalculateDistanceKM(user.Latitude, user.Longitude, getNearUsersDTO.Latitude, getNearUsersDTO.Longitude, "K") <= 20 && user.ID != userID && user.Longitude > 0 && user.Latitude > 0 {
-			nearUsers = append(nearUsers, user)
-		}
+	blockedUserIDs, err := service.getBlockedUserIDs(authUser)
+	if err != nil {
+		return nil, err
 	}
 
-	return nearUsers, nil
+	excludedUserIDs := append([]string{userID}, blockedUserIDs...)
+
+	return service.repository.GetNearbyUsers(getNearUsersDTO.Latitude, getNearUsersDTO.Longitude, nearUsersRadiusKM, nearUsersLimit, excludedUserIDs)
 }
 
-func (service *Service) DeleteUserFriend(userID, friendID string) (*model.User, error) {
+func (service *Service) AdminUpdateUserRole(authUser model.User, userID string, updateUserRoleDTO model.UpdateUserRoleDTO) (*model.User, error) {
+	if !utils.Contains(model.Roles, updateUserRoleDTO.Role) {
+		return nil, errors.InvalidRole
+	}
+
+	if authUser.ID == userID {
+		return nil, errors.Forbidden
+	}
+
 	user, err := service.repository.GetUser(userID)
 	if err != nil {
 		return nil, errors.UserNotFound
 	}
 
-	friend, err := service.repository.GetUser(friendID)
+	user.UserType = updateUserRoleDTO.Role
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	return service.repository.UpdateUser(userID, *user)
+}
+
+func (service *Service) AdminBanUser(authUser model.User, userID string, banUserDTO model.BanUserDTO) (*model.User, error) {
+	if authUser.ID == userID {
+		return nil, errors.Forbidden
+	}
+
+	user, err := service.repository.GetUser(userID)
 	if err != nil {
 		return nil, errors.UserNotFound
 	}
 
-	user.FriendIDs = utils.RemoveElement(user.FriendIDs, friend.ID)
-	updatedUser, err := service.repository.UpdateUser(user.ID, *user)
-	if err != nil {
-		return nil, err
+	// Staff accounts are managed through roles, only regular users can be banned.
+	if user.UserType != model.RoleUser {
+		return nil, errors.Forbidden
 	}
 
-	friend.FriendIDs = utils.RemoveElement(friend.FriendIDs, user.ID)
-	_, err = service.repository.UpdateUser(friend.ID, *friend)
+	user.IsBanned = banUserDTO.IsBanned
+	user.UpdatedAt = time.Now().UTC().Round(time.Minute)
+
+	updatedUser, err := service.repository.UpdateUser(userID, *user)
 	if err != nil {
 		return nil, err
 	}
 
+	if updatedUser.IsBanned {
+		err = service.LogoutAll(userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return updatedUser, nil
 }
+
+// generateUsername returns a free username derived from the name and surname. A random
+// suffix is added when the plain one is taken.
+func (service *Service) generateUsername(name, surname string) (string, error) {
+	base := utils.GetUsernameBase(name, surname)
+
+	username := base
+	for i := 0; i < 5; i++ {
+		_, err := service.repository.GetUserByUsername(username)
+		if err == errors.UserNotFound {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		username = base + "." + utils.GenerateSecureToken(2)
+	}
+
+	return "", errors.UsernameTaken
+}
+
+// AssignMissingUsernames gives a username to every user registered before usernames existed
+// and returns how many users got one.
+func (service *Service) AssignMissingUsernames() (int, error) {
+	users, err := service.repository.GetUsersWithoutUsername()
+	if err != nil {
+		return 0, err
+	}
+
+	assigned := 0
+	for _, user := range users {
+		username, err := service.generateUsername(user.Name, user.Surname)
+		if err != nil {
+			return assigned, err
+		}
+		err = service.repository.SetUsername(user.ID, username)
+		if err == errors.UsernameTaken {
+			// Another user took it meanwhile, the next run picks the user up again.
+			continue
+		}
+		if err != nil {
+			return assigned, err
+		}
+		assigned++
+	}
+
+	return assigned, nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/anilaydinn/socium-be/errors"
+)
+
+// LocalStorage keeps files in a directory, with the content type in a file next to each one.
+type LocalStorage struct {
+	Dir string
+}
+
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{Dir: dir}
+}
+
+func (localStorage *LocalStorage) Put(key, contentType string, data []byte) error {
+	path, err := localStorage.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path+".type", []byte(contentType), 0644)
+}
+
+func (localStorage *LocalStorage) Get(key string) ([]byte, string, error) {
+	path, err := localStorage.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, "", errors.MediaNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType, err := ioutil.ReadFile(path + ".type")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, "", err
+	}
+
+	return data, string(contentType), nil
+}
+
+func (localStorage *LocalStorage) Delete(key string) error {
+	path, err := localStorage.path(key)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []string{path, path + ".type"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// path keeps keys inside the storage directory, cleaning them as absolute paths drops any "..".
+func (localStorage *LocalStorage) path(key string) (string, error) {
+	cleanKey := filepath.Clean("/" + key)
+	if cleanKey == "/" {
+		return "", errors.MediaNotFound
+	}
+
+	return filepath.Join(localStorage.Dir, filepath.FromSlash(cleanKey)), nil
+}
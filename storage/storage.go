@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+// Storage keeps the files of uploaded media under keys such as "media/<id>/original".
+type Storage interface {
+	Put(key, contentType string, data []byte) error
+	// Get returns the file and its content type, or errors.MediaNotFound.
+	Get(key string) ([]byte, string, error)
+	Delete(key string) error
+}
+
+var currentStorage = struct {
+	sync.RWMutex
+	storage Storage
+}{}
+
+func SetStorage(storage Storage) {
+	currentStorage.Lock()
+	defer currentStorage.Unlock()
+
+	currentStorage.storage = storage
+}
+
+// GetStorage returns the configured storage, a local storage in MEDIA_DIR when none was set.
+func GetStorage() Storage {
+	currentStorage.RLock()
+	storage := currentStorage.storage
+	currentStorage.RUnlock()
+
+	if storage != nil {
+		return storage
+	}
+
+	currentStorage.Lock()
+	defer currentStorage.Unlock()
+
+	if currentStorage.storage == nil {
+		currentStorage.storage = NewLocalStorage(getMediaDir())
+	}
+
+	return currentStorage.storage
+}
+
+// SetupStorage selects the storage backend configured in the environment. STORAGE_BACKEND=s3
+// stores files in the S3_BUCKET of an S3 compatible service at S3_ENDPOINT, anything else
+// stores them on the local disk in MEDIA_DIR.
+func SetupStorage() {
+	if os.Getenv("STORAGE_BACKEND") != "s3" {
+		SetStorage(NewLocalStorage(getMediaDir()))
+		return
+	}
+
+	region := os.Getenv("S3_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	s3Storage := NewS3Storage(os.Getenv("S3_ENDPOINT"), os.Getenv("S3_BUCKET"), region, os.Getenv("S3_ACCESS_KEY_ID"), os.Getenv("S3_SECRET_ACCESS_KEY"))
+	if s3Storage.Endpoint == "" || s3Storage.Bucket == "" {
+		log.Println("S3_ENDPOINT or S3_BUCKET is not set, storing media on the local disk")
+		SetStorage(NewLocalStorage(getMediaDir()))
+		return
+	}
+
+	SetStorage(s3Storage)
+}
+
+func getMediaDir() string {
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		return "media"
+	}
+
+	return mediaDir
+}
@@ -0,0 +1,344 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCreatePostWithMultipleMedia(t *testing.T) {
+	Convey("Given a authenticated user with uploaded media", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		var mediaIDs []string
+		for i := 0; i < 2; i++ {
+			media := model.Media{
+				ID:          utils.GenerateUUID(0),
+				UserID:      registeredUser.ID,
+				ContentType: "image/jpeg",
+				CreatedAt:   time.Now().UTC().Round(time.Second),
+			}
+			testRepository.CreateMedia(media)
+			mediaIDs = append(mediaIDs, media.ID)
+		}
+
+		Convey("When user creates a post with two media items", func() {
+			postDTO := model.PostDTO{
+				Description: "Post description",
+				Media: []model.PostMediaDTO{
+					{MediaID: mediaIDs[1], Caption: "Sunset", AltText: "The sun setting over the sea"},
+					{MediaID: mediaIDs[0], Caption: "Beach"},
+				},
+				Audience: model.AudiencePublic,
+			}
+			reqBody, err := json.Marshal(postDTO)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/posts", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken("3c0bbdae", "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then the media should be kept in order with their captions", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+				actualResult := model.Post{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &actualResult)
+				So(err, ShouldBeNil)
+
+				So(actualResult.Media, ShouldHaveLength, 2)
+				So(actualResult.Media[0].MediaID, ShouldEqual, mediaIDs[1])
+				So(actualResult.Media[0].Caption, ShouldEqual, "Sunset")
+				So(actualResult.Media[0].AltText, ShouldEqual, "The sun setting over the sea")
+				So(actualResult.Media[1].MediaID, ShouldEqual, mediaIDs[0])
+				So(actualResult.Image, ShouldEqual, mediaIDs[1])
+			})
+		})
+
+		Convey("When user creates a post with the same media twice", func() {
+			postDTO := model.PostDTO{
+				Description: "Post description",
+				Media: []model.PostMediaDTO{
+					{MediaID: mediaIDs[0]},
+					{MediaID: mediaIDs[0]},
+				},
+			}
+			reqBody, err := json.Marshal(postDTO)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/posts", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken("3c0bbdae", "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 400", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+		})
+
+		Convey("When user edits only the description of a post with two media items", func() {
+			post := model.Post{
+				ID:          utils.GenerateUUID(8),
+				UserID:      registeredUser.ID,
+				Description: "Post description",
+				Image:       mediaIDs[0],
+				Media: []model.PostMedia{
+					{MediaID: mediaIDs[0], Caption: "Beach"},
+					{MediaID: mediaIDs[1], Caption: "Sunset"},
+				},
+				Audience:  model.AudiencePublic,
+				CreatedAt: time.Now().UTC().Round(time.Second),
+				UpdatedAt: time.Now().UTC().Round(time.Second),
+			}
+			testRepository.CreatePost(post)
+
+			reqBody := []byte(`{"description":"Edited description"}`)
+
+			req, _ := http.NewRequest(http.MethodPatch, "/user/posts/"+post.ID, bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken("3c0bbdae", "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then the media should be kept", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.Post{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &actualResult)
+				So(err, ShouldBeNil)
+
+				So(actualResult.Description, ShouldEqual, "Edited description")
+				So(actualResult.Media, ShouldHaveLength, 2)
+				So(actualResult.Media[0].MediaID, ShouldEqual, mediaIDs[0])
+				So(actualResult.Media[1].MediaID, ShouldEqual, mediaIDs[1])
+				So(actualResult.Image, ShouldEqual, mediaIDs[0])
+			})
+		})
+	})
+}
+
+func TestPhotosAndAlbums(t *testing.T) {
+	Convey("Given a user with public and friends only photos", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		owner := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		stranger := model.User{
+			ID:          "123123",
+			Name:        "Mehmet",
+			Surname:     "Bond",
+			Email:       "test1@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(owner)
+		testRepository.RegisterUser(stranger)
+
+		var mediaIDs []string
+		for i := 0; i < 3; i++ {
+			media := model.Media{
+				ID:          utils.GenerateUUID(0),
+				UserID:      owner.ID,
+				ContentType: "image/jpeg",
+				CreatedAt:   time.Now().UTC().Round(time.Second),
+			}
+			testRepository.CreateMedia(media)
+			mediaIDs = append(mediaIDs, media.ID)
+		}
+
+		publicPost := model.Post{
+			ID:     utils.GenerateUUID(8),
+			UserID: owner.ID,
+			Image:  mediaIDs[0],
+			Media: []model.PostMedia{
+				{MediaID: mediaIDs[0], Caption: "First"},
+				{MediaID: mediaIDs[1], Caption: "Second"},
+			},
+			Audience:  model.AudiencePublic,
+			CreatedAt: time.Now().UTC().Add(-time.Hour).Round(time.Second),
+			UpdatedAt: time.Now().UTC().Add(-time.Hour).Round(time.Second),
+		}
+		friendsPost := model.Post{
+			ID:        utils.GenerateUUID(8),
+			UserID:    owner.ID,
+			Image:     mediaIDs[2],
+			Media:     []model.PostMedia{{MediaID: mediaIDs[2], Caption: "Private"}},
+			Audience:  model.AudienceFriends,
+			IsPrivate: true,
+			CreatedAt: time.Now().UTC().Round(time.Second),
+			UpdatedAt: time.Now().UTC().Round(time.Second),
+		}
+		testRepository.CreatePost(publicPost)
+		testRepository.CreatePost(friendsPost)
+
+		Convey("When a stranger lists the photos of the user one by one", func() {
+			var photos []model.Photo
+			cursor := ""
+			for {
+				req, _ := http.NewRequest(http.MethodGet, "/user/users/"+owner.ID+"/photos?limit=1&cursor="+cursor, nil)
+				req.Header.Add("Authorization", GetBearerToken(stranger.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				page := model.PhotosCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &page), ShouldBeNil)
+
+				photos = append(photos, page.Photos...)
+				if len(page.NextCursor) == 0 {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			Convey("Then only the public photos should be listed in post order", func() {
+				So(photos, ShouldHaveLength, 2)
+				So(photos[0].MediaID, ShouldEqual, mediaIDs[0])
+				So(photos[0].Caption, ShouldEqual, "First")
+				So(photos[0].Media, ShouldNotBeNil)
+				So(photos[1].MediaID, ShouldEqual, mediaIDs[1])
+			})
+		})
+
+		Convey("When the owner puts a public and a friends only photo in an album", func() {
+			album, err := service.CreateAlbum(owner, model.AlbumDTO{Name: "Holiday"})
+			So(err, ShouldBeNil)
+			privateAlbum, err := service.CreateAlbum(owner, model.AlbumDTO{Name: "Private"})
+			So(err, ShouldBeNil)
+
+			_, err = service.AddAlbumItem(owner, album.ID, model.AlbumItemDTO{PostID: publicPost.ID, MediaID: mediaIDs[1]})
+			So(err, ShouldBeNil)
+			_, err = service.AddAlbumItem(owner, album.ID, model.AlbumItemDTO{PostID: friendsPost.ID, MediaID: mediaIDs[2]})
+			So(err, ShouldBeNil)
+			_, err = service.AddAlbumItem(owner, privateAlbum.ID, model.AlbumItemDTO{PostID: friendsPost.ID, MediaID: mediaIDs[2]})
+			So(err, ShouldBeNil)
+
+			Convey("Then the owner should see every photo", func() {
+				ownerAlbum, err := service.GetAlbum(owner, album.ID)
+				So(err, ShouldBeNil)
+				So(ownerAlbum.PhotoCount, ShouldEqual, 2)
+			})
+
+			Convey("Then a stranger should only see the public photo", func() {
+				req, _ := http.NewRequest(http.MethodGet, "/user/albums/"+album.ID, nil)
+				req.Header.Add("Authorization", GetBearerToken(stranger.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.Album{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult.PhotoCount, ShouldEqual, 1)
+				So(actualResult.Photos, ShouldHaveLength, 1)
+				So(actualResult.Photos[0].MediaID, ShouldEqual, mediaIDs[1])
+				So(actualResult.CoverPhoto.MediaID, ShouldEqual, mediaIDs[1])
+			})
+
+			Convey("Then a stranger should not see the album without visible photos", func() {
+				req, _ := http.NewRequest(http.MethodGet, "/user/users/"+owner.ID+"/albums", nil)
+				req.Header.Add("Authorization", GetBearerToken(stranger.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				var actualResult []model.Album
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult, ShouldHaveLength, 1)
+				So(actualResult[0].ID, ShouldEqual, album.ID)
+
+				_, err = service.GetAlbum(stranger, privateAlbum.ID)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then deleting the post should take its photos out of the album", func() {
+				So(service.DeleteAdminUserPost(publicPost.ID, owner.ID), ShouldBeNil)
+
+				ownerAlbum, err := service.GetAlbum(owner, album.ID)
+				So(err, ShouldBeNil)
+				So(ownerAlbum.PhotoCount, ShouldEqual, 1)
+
+				storedAlbum, err := testRepository.GetAlbum(album.ID)
+				So(err, ShouldBeNil)
+				So(storedAlbum.Items, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a stranger adds a photo of the user to their own album", func() {
+			album, err := service.CreateAlbum(stranger, model.AlbumDTO{Name: "Collected"})
+			So(err, ShouldBeNil)
+
+			reqBody, _ := json.Marshal(model.AlbumItemDTO{PostID: publicPost.ID, MediaID: mediaIDs[0]})
+			req, _ := http.NewRequest(http.MethodPost, "/user/albums/"+album.ID+"/items", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken(stranger.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 400", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+		})
+	})
+}
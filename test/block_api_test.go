@@ -0,0 +1,368 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBlockUser(t *testing.T) {
+	Convey("Given two friends and a third user", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		blocker := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			FriendIDs:   []string{"123123"},
+			UserType:    "user",
+			IsActivated: true,
+		}
+		blocked := model.User{
+			ID:          "123123",
+			Name:        "Mehmet",
+			Surname:     "Bond",
+			Email:       "test1@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			FriendIDs:   []string{"3c0bbdae"},
+			UserType:    "user",
+			IsActivated: true,
+		}
+		other := model.User{
+			ID:          "321321",
+			Name:        "Ahmet",
+			Surname:     "Bond",
+			Email:       "test2@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(blocker)
+		testRepository.RegisterUser(blocked)
+		testRepository.RegisterUser(other)
+
+		now := time.Now().UTC().Round(time.Second)
+		testRepository.CreateFriendRequest(model.Friendship{
+			ID:          "fr13nd01",
+			RequesterID: blocked.ID,
+			AddresseeID: blocker.ID,
+			Status:      model.FriendshipAccepted,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		testRepository.CreatePost(model.Post{
+			ID:        "p0st0001",
+			UserID:    blocker.ID,
+			Audience:  model.AudiencePublic,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		testRepository.CreatePost(model.Post{
+			ID:         "p0st0002",
+			UserID:     other.ID,
+			Audience:   model.AudiencePublic,
+			CommentIDs: []string{"c0mm3nt1"},
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		testRepository.AddComment(model.Comment{
+			ID:        "c0mm3nt1",
+			UserID:    blocker.ID,
+			PostID:    "p0st0002",
+			Content:   "Comment",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+
+		get := func(path, userID string) *http.Response {
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			req.Header.Add("Authorization", GetBearerToken(userID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			return res
+		}
+
+		Convey("When the user blocks their friend", func() {
+			req, _ := http.NewRequest(http.MethodPost, "/user/users/"+blocker.ID+"/blockedUsers/"+blocked.ID, nil)
+			req.Header.Add("Authorization", GetBearerToken(blocker.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+			Convey("Then they should no longer be friends", func() {
+				blockerUser, err := testRepository.GetUser(blocker.ID)
+				So(err, ShouldBeNil)
+				So(blockerUser.FriendIDs, ShouldBeEmpty)
+
+				blockedUser, err := testRepository.GetUser(blocked.ID)
+				So(err, ShouldBeNil)
+				So(blockedUser.FriendIDs, ShouldBeEmpty)
+				So(blockedUser.BlockedByUserIDs, ShouldResemble, []string{blocker.ID})
+
+				friendship, err := testRepository.GetFriendship(blocker.ID, blocked.ID)
+				So(err, ShouldBeNil)
+				So(friendship.Status, ShouldEqual, model.FriendshipBlocked)
+			})
+
+			Convey("Then the blocked user should not see the blocker's profile or posts", func() {
+				So(get("/user/users/"+blocker.ID, blocked.ID).StatusCode, ShouldEqual, fiber.StatusNotFound)
+
+				res := get("/user/posts?userId="+blocker.ID, blocked.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.PostsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.Posts, ShouldBeEmpty)
+			})
+
+			Convey("Then the blocked user should not see the blocker's comments", func() {
+				res := get("/user/posts/p0st0002/comments", blocked.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.CommentsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.Comments, ShouldBeEmpty)
+
+				So(get("/user/posts/p0st0002/comments", other.ID).StatusCode, ShouldEqual, fiber.StatusOK)
+			})
+
+			Convey("Then the replies of the blocker should not be counted for the blocked user", func() {
+				for _, comment := range []model.Comment{
+					{ID: "c0mm3nt2", UserID: other.ID, PostID: "p0st0002", Content: "Comment", CreatedAt: now, UpdatedAt: now},
+					{ID: "c0mm3nt3", UserID: blocker.ID, PostID: "p0st0002", ParentCommentID: "c0mm3nt2", Content: "Reply", CreatedAt: now, UpdatedAt: now},
+				} {
+					testRepository.AddComment(comment)
+				}
+
+				getComments := func(userID string) model.CommentsCursorResponse {
+					res := get("/user/posts/p0st0002/comments", userID)
+					So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+					actualResult := model.CommentsCursorResponse{}
+					httpResponseBody, _ := ioutil.ReadAll(res.Body)
+					So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+					return actualResult
+				}
+
+				blockedResult := getComments(blocked.ID)
+				So(blockedResult.Comments, ShouldHaveLength, 1)
+				So(blockedResult.Comments[0].ID, ShouldEqual, "c0mm3nt2")
+				So(blockedResult.Comments[0].ReplyCount, ShouldEqual, 0)
+
+				otherResult := getComments(other.ID)
+				So(otherResult.Comments, ShouldHaveLength, 2)
+				for _, comment := range otherResult.Comments {
+					if comment.ID == "c0mm3nt2" {
+						So(comment.ReplyCount, ShouldEqual, 1)
+					}
+				}
+			})
+
+			Convey("Then the post returned after commenting should leave out the blocker's comments", func() {
+				reqBody, err := json.Marshal(model.CommentDTO{Content: "New comment"})
+				So(err, ShouldBeNil)
+
+				req, _ := http.NewRequest(http.MethodPost, "/user/posts/p0st0002/comments", bytes.NewReader(reqBody))
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("Authorization", GetBearerToken(blocked.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+				actualResult := model.Post{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.Comments, ShouldHaveLength, 1)
+				So(actualResult.Comments[0].Content, ShouldEqual, "New comment")
+			})
+
+			Convey("Then neither user should find the other when searching", func() {
+				res := get("/user/users?filter=James", blocked.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := []model.PublicUserView{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult, ShouldBeEmpty)
+
+				res = get("/user/users?filter=Mehmet", blocker.ID)
+				httpResponseBody, _ = ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult, ShouldBeEmpty)
+			})
+
+			Convey("Then friend requests between them should be rejected", func() {
+				req, _ := http.NewRequest(http.MethodPost, "/user/users/"+blocker.ID+"/friendRequests", bytes.NewReader([]byte("{}")))
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("Authorization", GetBearerToken(blocked.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusNotFound)
+
+				req, _ = http.NewRequest(http.MethodPost, "/user/users/"+blocked.ID+"/friendRequests", bytes.NewReader([]byte("{}")))
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("Authorization", GetBearerToken(blocker.ID, "user"))
+
+				res, err = app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+
+			Convey("Then the blocker should list the blocked user", func() {
+				res := get("/user/users/"+blocker.ID+"/blockedUsers", blocker.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := []model.PublicUserView{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult, ShouldHaveLength, 1)
+				So(actualResult[0].ID, ShouldEqual, blocked.ID)
+			})
+
+			Convey("When the blocker unblocks the user", func() {
+				req, _ := http.NewRequest(http.MethodDelete, "/user/users/"+blocker.ID+"/blockedUsers/"+blocked.ID, nil)
+				req.Header.Add("Authorization", GetBearerToken(blocker.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+				Convey("Then the blocked user should see the blocker's profile again", func() {
+					So(get("/user/users/"+blocker.ID, blocked.ID).StatusCode, ShouldEqual, fiber.StatusOK)
+
+					friendship, err := testRepository.GetFriendship(blocker.ID, blocked.ID)
+					So(err, ShouldBeNil)
+					So(friendship, ShouldBeNil)
+				})
+			})
+		})
+
+		Convey("When the user blocks themselves", func() {
+			req, _ := http.NewRequest(http.MethodPost, "/user/users/"+blocker.ID+"/blockedUsers/"+blocker.ID, nil)
+			req.Header.Add("Authorization", GetBearerToken(blocker.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 400", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+		})
+	})
+}
+
+func TestMuteUser(t *testing.T) {
+	Convey("Given two friends with public posts", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		registeredUser1 := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			FriendIDs:   []string{"123123"},
+			UserType:    "user",
+			IsActivated: true,
+		}
+		registeredUser2 := model.User{
+			ID:          "123123",
+			Name:        "Mehmet",
+			Surname:     "Bond",
+			Email:       "test1@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			FriendIDs:   []string{"3c0bbdae"},
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser1)
+		testRepository.RegisterUser(registeredUser2)
+
+		now := time.Now().UTC().Round(time.Second)
+		testRepository.CreatePost(model.Post{
+			ID:        "p0st0001",
+			UserID:    registeredUser2.ID,
+			Audience:  model.AudiencePublic,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+
+		getPosts := func(query string) []model.Post {
+			req, _ := http.NewRequest(http.MethodGet, "/user/posts?"+query, nil)
+			req.Header.Add("Authorization", GetBearerToken(registeredUser1.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			actualResult := model.PostsCursorResponse{}
+			httpResponseBody, _ := ioutil.ReadAll(res.Body)
+			So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+			return actualResult.Posts
+		}
+
+		Convey("When the user mutes their friend", func() {
+			req, _ := http.NewRequest(http.MethodPost, "/user/users/"+registeredUser1.ID+"/mutedUsers/"+registeredUser2.ID, nil)
+			req.Header.Add("Authorization", GetBearerToken(registeredUser1.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			Convey("Then the friend's posts should be left out of the home feed only", func() {
+				So(getPosts("userId="+registeredUser1.ID+"&homepage=true"), ShouldBeEmpty)
+				So(getPosts("userId="+registeredUser2.ID), ShouldHaveLength, 1)
+			})
+
+			Convey("Then they should still be friends", func() {
+				user, err := testRepository.GetUser(registeredUser1.ID)
+				So(err, ShouldBeNil)
+				So(user.FriendIDs, ShouldResemble, []string{registeredUser2.ID})
+				So(user.MutedUserIDs, ShouldResemble, []string{registeredUser2.ID})
+			})
+
+			Convey("When the user unmutes their friend", func() {
+				req, _ := http.NewRequest(http.MethodDelete, "/user/users/"+registeredUser1.ID+"/mutedUsers/"+registeredUser2.ID, nil)
+				req.Header.Add("Authorization", GetBearerToken(registeredUser1.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				Convey("Then the friend's posts should be back in the home feed", func() {
+					So(getPosts("userId="+registeredUser1.ID+"&homepage=true"), ShouldHaveLength, 1)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,375 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetPostComments(t *testing.T) {
+	Convey("Given a post with comments and replies", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		post := model.Post{
+			ID:         utils.GenerateUUID(8),
+			UserID:     registeredUser.ID,
+			Audience:   model.AudiencePublic,
+			CommentIDs: []string{"c0mm3nt1", "c0mm3nt2", "c0mm3nt3", "r3ply001"},
+			CreatedAt:  time.Now().UTC().Add(-5 * time.Minute).Round(time.Second),
+			UpdatedAt:  time.Now().UTC().Add(-5 * time.Minute).Round(time.Second),
+		}
+		testRepository.CreatePost(post)
+
+		for i, commentID := range []string{"c0mm3nt1", "c0mm3nt2", "c0mm3nt3"} {
+			testRepository.AddComment(model.Comment{
+				ID:        commentID,
+				UserID:    registeredUser.ID,
+				PostID:    post.ID,
+				Content:   "Comment " + strconv.Itoa(i+1),
+				CreatedAt: time.Now().UTC().Add(time.Duration(i-4) * time.Minute).Round(time.Second),
+				UpdatedAt: time.Now().UTC().Add(time.Duration(i-4) * time.Minute).Round(time.Second),
+			})
+		}
+		testRepository.AddComment(model.Comment{
+			ID:              "r3ply001",
+			UserID:          registeredUser.ID,
+			PostID:          post.ID,
+			ParentCommentID: "c0mm3nt1",
+			Content:         "Reply",
+			CreatedAt:       time.Now().UTC().Round(time.Second),
+			UpdatedAt:       time.Now().UTC().Round(time.Second),
+		})
+
+		bearerToken := GetBearerToken(registeredUser.ID, "user")
+
+		Convey("When user gets the first page of comments", func() {
+			req, err := http.NewRequest(http.MethodGet, "/user/posts/"+post.ID+"/comments?limit=2", nil)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			firstPage := model.CommentsCursorResponse{}
+			httpResponseBody, _ := ioutil.ReadAll(res.Body)
+			err = json.Unmarshal(httpResponseBody, &firstPage)
+			So(err, ShouldBeNil)
+
+			Convey("Then oldest top level comments should return", func() {
+				So(firstPage.Comments, ShouldHaveLength, 2)
+				So(firstPage.Comments[0].ID, ShouldEqual, "c0mm3nt1")
+				So(firstPage.Comments[0].ReplyCount, ShouldEqual, 1)
+				So(firstPage.Comments[0].User, ShouldResemble, GetPublicUserView(registeredUser))
+				So(firstPage.Comments[1].ID, ShouldEqual, "c0mm3nt2")
+				So(firstPage.NextCursor, ShouldNotBeEmpty)
+			})
+
+			Convey("When user gets the next page", func() {
+				req, err := http.NewRequest(http.MethodGet, "/user/posts/"+post.ID+"/comments?limit=2&cursor="+firstPage.NextCursor, nil)
+				req.Header.Add("Authorization", bearerToken)
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+
+				Convey("Then the remaining comment should return", func() {
+					secondPage := model.CommentsCursorResponse{}
+					httpResponseBody, _ := ioutil.ReadAll(res.Body)
+					err := json.Unmarshal(httpResponseBody, &secondPage)
+					So(err, ShouldBeNil)
+
+					So(secondPage.Comments, ShouldHaveLength, 1)
+					So(secondPage.Comments[0].ID, ShouldEqual, "c0mm3nt3")
+					So(secondPage.NextCursor, ShouldBeEmpty)
+				})
+			})
+		})
+
+		Convey("When user gets the replies of a comment", func() {
+			req, err := http.NewRequest(http.MethodGet, "/user/posts/"+post.ID+"/comments?parentCommentId=c0mm3nt1", nil)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then the replies should return", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				response := model.CommentsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &response)
+				So(err, ShouldBeNil)
+
+				So(response.Comments, ShouldHaveLength, 1)
+				So(response.Comments[0].ID, ShouldEqual, "r3ply001")
+				So(response.Comments[0].ParentCommentID, ShouldEqual, "c0mm3nt1")
+			})
+		})
+
+		Convey("When user gets the posts", func() {
+			req, err := http.NewRequest(http.MethodGet, "/user/posts?userId="+registeredUser.ID, nil)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then the post should come with the comment count and top level comments only", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				response := model.PostsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &response)
+				So(err, ShouldBeNil)
+
+				So(response.Posts, ShouldHaveLength, 1)
+				So(response.Posts[0].CommentCount, ShouldEqual, 4)
+				So(response.Posts[0].Comments, ShouldHaveLength, 3)
+				So(response.Posts[0].Comments[0].ID, ShouldEqual, "c0mm3nt1")
+				So(response.Posts[0].Comments[0].ReplyCount, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestReplyToComment(t *testing.T) {
+	Convey("Given a post with a comment", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		post := model.Post{
+			ID:         utils.GenerateUUID(8),
+			UserID:     registeredUser.ID,
+			Audience:   model.AudiencePublic,
+			CommentIDs: []string{"c0mm3nt1"},
+			CreatedAt:  time.Now().UTC().Round(time.Second),
+			UpdatedAt:  time.Now().UTC().Round(time.Second),
+		}
+		testRepository.CreatePost(post)
+		testRepository.AddComment(model.Comment{
+			ID:        "c0mm3nt1",
+			UserID:    registeredUser.ID,
+			PostID:    post.ID,
+			Content:   "Comment",
+			CreatedAt: time.Now().UTC().Round(time.Second),
+			UpdatedAt: time.Now().UTC().Round(time.Second),
+		})
+
+		addComment := func(commentDTO model.CommentDTO) *http.Response {
+			reqBody, err := json.Marshal(commentDTO)
+			So(err, ShouldBeNil)
+
+			req, err := http.NewRequest(http.MethodPost, "/user/posts/"+post.ID+"/comments", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken(registeredUser.ID, "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			return res
+		}
+
+		Convey("When user replies to the comment", func() {
+			res := addComment(model.CommentDTO{ParentCommentID: "c0mm3nt1", Content: "Reply"})
+
+			Convey("Then the reply should be added to the post", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+				actualResult := model.Post{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &actualResult)
+				So(err, ShouldBeNil)
+
+				So(actualResult.CommentCount, ShouldEqual, 2)
+				So(actualResult.Comments, ShouldHaveLength, 1)
+				So(actualResult.Comments[0].ID, ShouldEqual, "c0mm3nt1")
+				So(actualResult.Comments[0].ReplyCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When user replies to a missing comment", func() {
+			res := addComment(model.CommentDTO{ParentCommentID: "missing1", Content: "Reply"})
+
+			Convey("Then status code should be 404", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestUpdateAndDeleteComment(t *testing.T) {
+	Convey("Given a post with comments of different users", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		postOwner := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		commentAuthor := model.User{
+			ID:          "2dbbds32",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test2@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		otherUser := model.User{
+			ID:          "5d1cd8a1",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test3@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(postOwner)
+		testRepository.RegisterUser(commentAuthor)
+		testRepository.RegisterUser(otherUser)
+
+		post := model.Post{
+			ID:         utils.GenerateUUID(8),
+			UserID:     postOwner.ID,
+			Audience:   model.AudiencePublic,
+			CommentIDs: []string{"c0mm3nt1", "r3ply001"},
+			CreatedAt:  time.Now().UTC().Round(time.Second),
+			UpdatedAt:  time.Now().UTC().Round(time.Second),
+		}
+		testRepository.CreatePost(post)
+		testRepository.AddComment(model.Comment{
+			ID:        "c0mm3nt1",
+			UserID:    commentAuthor.ID,
+			PostID:    post.ID,
+			Content:   "Comment",
+			CreatedAt: time.Now().UTC().Add(-1 * time.Minute).Round(time.Second),
+			UpdatedAt: time.Now().UTC().Add(-1 * time.Minute).Round(time.Second),
+		})
+		testRepository.AddComment(model.Comment{
+			ID:              "r3ply001",
+			UserID:          otherUser.ID,
+			PostID:          post.ID,
+			ParentCommentID: "c0mm3nt1",
+			Content:         "Reply",
+			CreatedAt:       time.Now().UTC().Round(time.Second),
+			UpdatedAt:       time.Now().UTC().Round(time.Second),
+		})
+
+		sendRequest := func(method, userID string, body interface{}) *http.Response {
+			reqBody, err := json.Marshal(body)
+			So(err, ShouldBeNil)
+
+			req, err := http.NewRequest(method, "/user/posts/"+post.ID+"/comments/c0mm3nt1", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken(userID, "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			return res
+		}
+
+		Convey("When the comment author edits the comment", func() {
+			res := sendRequest(http.MethodPatch, commentAuthor.ID, model.CommentDTO{Content: "Edited comment"})
+
+			Convey("Then edited comment should return", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.Comment{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &actualResult)
+				So(err, ShouldBeNil)
+
+				So(actualResult.Content, ShouldEqual, "Edited comment")
+				So(actualResult.IsEdited, ShouldBeTrue)
+				So(actualResult.ReplyCount, ShouldEqual, 1)
+				So(actualResult.User, ShouldResemble, GetPublicUserView(commentAuthor))
+			})
+		})
+
+		Convey("When the post owner edits the comment", func() {
+			res := sendRequest(http.MethodPatch, postOwner.ID, model.CommentDTO{Content: "Edited comment"})
+
+			Convey("Then status code should be 403", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+		})
+
+		Convey("When another user deletes the comment", func() {
+			res := sendRequest(http.MethodDelete, otherUser.ID, nil)
+
+			Convey("Then status code should be 403", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+		})
+
+		Convey("When the post owner deletes the comment", func() {
+			res := sendRequest(http.MethodDelete, postOwner.ID, nil)
+
+			Convey("Then the comment and its replies should be deleted", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+				commentCount, err := testRepository.GetCommentCount()
+				So(err, ShouldBeNil)
+				So(commentCount, ShouldEqual, 0)
+
+				updatedPost, err := testRepository.GetPost(post.ID)
+				So(err, ShouldBeNil)
+				So(updatedPost.CommentIDs, ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,202 @@
+package test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/errors"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/utils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConcurrentUpdates(t *testing.T) {
+	Convey("Given a post and many users", t, func() {
+		testRepository := GetCleanTestRepository()
+		service := service.NewService(testRepository)
+
+		owner := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(owner)
+
+		const userCount = 25
+		var users []model.User
+		for i := 0; i < userCount; i++ {
+			user := model.User{
+				ID:          "user" + strconv.Itoa(i),
+				Name:        "User",
+				Surname:     strconv.Itoa(i),
+				Email:       "user" + strconv.Itoa(i) + "@gmail.com",
+				UserType:    "user",
+				IsActivated: true,
+			}
+			testRepository.RegisterUser(user)
+			users = append(users, user)
+		}
+
+		post := model.Post{
+			ID:          utils.GenerateUUID(8),
+			UserID:      owner.ID,
+			Description: "Hello",
+			Audience:    model.AudiencePublic,
+			CommentIDs:  []string{},
+			CreatedAt:   time.Now().UTC().Round(time.Second),
+			UpdatedAt:   time.Now().UTC().Round(time.Second),
+		}
+		testRepository.CreatePost(post)
+
+		Convey("When every user likes, comments and sends a friend request at the same time", func() {
+			errs := make(chan error, 3*userCount)
+			var wg sync.WaitGroup
+			for _, user := range users {
+				wg.Add(3)
+				go func(user model.User) {
+					defer wg.Done()
+					_, err := service.LikePost(user, post.ID, model.LikePostDTO{})
+					errs <- err
+				}(user)
+				go func(user model.User) {
+					defer wg.Done()
+					_, err := service.AddPostComment(user, post.ID, model.CommentDTO{Content: "Comment"})
+					errs <- err
+				}(user)
+				go func(user model.User) {
+					defer wg.Done()
+					_, err := service.SendFriendRequest(user, owner.ID, model.FriendRequestDTO{})
+					errs <- err
+				}(user)
+			}
+			wg.Wait()
+			close(errs)
+
+			Convey("Then no update should fail or be lost", func() {
+				for err := range errs {
+					So(err, ShouldBeNil)
+				}
+
+				reactionCounts, err := testRepository.GetReactionCounts(model.ReactionTargetPost, []string{post.ID})
+				So(err, ShouldBeNil)
+				So(reactionCounts[post.ID][model.ReactionLike], ShouldEqual, userCount)
+
+				updatedPost, err := testRepository.GetPost(post.ID)
+				So(err, ShouldBeNil)
+				So(updatedPost.CommentIDs, ShouldHaveLength, userCount)
+
+				friendRequests, err := testRepository.GetIncomingFriendRequests(owner.ID)
+				So(err, ShouldBeNil)
+				So(friendRequests, ShouldHaveLength, userCount)
+			})
+		})
+
+		Convey("When the owner sends many edits of the same version at the same time", func() {
+			readPost, err := testRepository.GetPost(post.ID)
+			So(err, ShouldBeNil)
+
+			const editCount = 10
+			errs := make(chan error, editCount)
+			var wg sync.WaitGroup
+			for i := 0; i < editCount; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, err := service.UpdatePost(owner, post.ID, model.UpdatePostDTO{
+						Description: "Edit " + strconv.Itoa(i),
+						Version:     readPost.Version,
+					})
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			Convey("Then only one edit should succeed and the others should conflict", func() {
+				succeeded := 0
+				for err := range errs {
+					if err == nil {
+						succeeded++
+					} else {
+						So(err, ShouldEqual, errors.VersionConflict)
+					}
+				}
+				So(succeeded, ShouldEqual, 1)
+
+				updatedPost, err := testRepository.GetPost(post.ID)
+				So(err, ShouldBeNil)
+				So(updatedPost.Version, ShouldEqual, readPost.Version+1)
+			})
+		})
+	})
+}
+
+func TestConcurrentFailedLogins(t *testing.T) {
+	Convey("Given a registered user", t, func() {
+		testRepository := GetCleanTestRepository()
+		service := service.NewService(testRepository)
+
+		testRepository.RegisterUser(model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		})
+
+		Convey("When many wrong passwords are sent at the same time", func() {
+			const loginCount = 30
+			errs := make(chan error, loginCount)
+			var wg sync.WaitGroup
+			for i := 0; i < loginCount; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, _, err := service.LoginUser(model.UserCredentialsDTO{
+						Email:    "test@gmail.com",
+						Password: "wrong" + strconv.Itoa(i),
+					}, "0.0.0.0")
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			Convey("Then every checked password should be counted and the account locked", func() {
+				wrongPasswords := 0
+				for err := range errs {
+					if err == errors.WrongPassword {
+						wrongPasswords++
+					} else {
+						So(err, ShouldEqual, errors.TooManyAttempts)
+					}
+				}
+				So(wrongPasswords, ShouldBeGreaterThanOrEqualTo, 5)
+
+				accountAttempt, err := testRepository.GetLoginAttempt("login:account:test@gmail.com")
+				So(err, ShouldBeNil)
+				So(accountAttempt.FailedCount, ShouldEqual, wrongPasswords)
+				So(accountAttempt.LockedUntil, ShouldHappenAfter, time.Now())
+
+				ipAttempt, err := testRepository.GetLoginAttempt("login:ip:0.0.0.0")
+				So(err, ShouldBeNil)
+				So(ipAttempt.FailedCount, ShouldEqual, wrongPasswords)
+
+				_, _, err = service.LoginUser(model.UserCredentialsDTO{
+					Email:    "test@gmail.com",
+					Password: "wrong",
+				}, "0.0.0.0")
+				So(err, ShouldEqual, errors.TooManyAttempts)
+			})
+		})
+	})
+}
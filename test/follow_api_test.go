@@ -0,0 +1,156 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFollowUser(t *testing.T) {
+	Convey("Given a user and a creator with a public and a friends only post", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		follower := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		creator := model.User{
+			ID:          "123123",
+			Name:        "Mehmet",
+			Surname:     "Bond",
+			Email:       "test1@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(follower)
+		testRepository.RegisterUser(creator)
+
+		now := time.Now().UTC().Round(time.Second)
+		testRepository.CreatePost(model.Post{
+			ID:        "p0st0001",
+			UserID:    creator.ID,
+			Audience:  model.AudiencePublic,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		testRepository.CreatePost(model.Post{
+			ID:        "p0st0002",
+			UserID:    creator.ID,
+			Audience:  model.AudienceFriends,
+			CreatedAt: now.Add(time.Second),
+			UpdatedAt: now.Add(time.Second),
+		})
+
+		send := func(method, path string) *http.Response {
+			req, _ := http.NewRequest(method, path, nil)
+			req.Header.Add("Authorization", GetBearerToken(follower.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			return res
+		}
+
+		getHomeFeed := func() []model.Post {
+			res := send(http.MethodGet, "/user/posts?userId="+follower.ID+"&homepage=true")
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			actualResult := model.PostsCursorResponse{}
+			httpResponseBody, _ := ioutil.ReadAll(res.Body)
+			So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+			return actualResult.Posts
+		}
+
+		Convey("When the user follows the creator", func() {
+			res := send(http.MethodPost, "/user/users/"+follower.ID+"/following/"+creator.ID)
+			So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+			Convey("Then the home feed should include the creator's public post only", func() {
+				posts := getHomeFeed()
+				So(posts, ShouldHaveLength, 1)
+				So(posts[0].ID, ShouldEqual, "p0st0001")
+			})
+
+			Convey("Then the creator's profile should show the follow", func() {
+				res := send(http.MethodGet, "/user/users/"+creator.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.ProfileUserView{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.FollowerCount, ShouldEqual, 1)
+				So(actualResult.FollowingCount, ShouldEqual, 0)
+				So(actualResult.IsFollowing, ShouldBeTrue)
+			})
+
+			Convey("Then the follower should be listed among the creator's followers", func() {
+				res := send(http.MethodGet, "/user/users/"+creator.ID+"/followers")
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.FollowsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.Follows, ShouldHaveLength, 1)
+				So(actualResult.Follows[0].User.ID, ShouldEqual, follower.ID)
+			})
+
+			Convey("Then the creator should be listed among the users the follower follows", func() {
+				res := send(http.MethodGet, "/user/users/"+follower.ID+"/following")
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.FollowsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.Follows, ShouldHaveLength, 1)
+				So(actualResult.Follows[0].User.ID, ShouldEqual, creator.ID)
+			})
+
+			Convey("Then following the creator again should return 409", func() {
+				res := send(http.MethodPost, "/user/users/"+follower.ID+"/following/"+creator.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusConflict)
+			})
+
+			Convey("When the user unfollows the creator", func() {
+				res := send(http.MethodDelete, "/user/users/"+follower.ID+"/following/"+creator.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+				Convey("Then the home feed should no longer include the creator's posts", func() {
+					So(getHomeFeed(), ShouldBeEmpty)
+				})
+
+				Convey("Then unfollowing the creator again should return 404", func() {
+					res := send(http.MethodDelete, "/user/users/"+follower.ID+"/following/"+creator.ID)
+					So(res.StatusCode, ShouldEqual, fiber.StatusNotFound)
+				})
+			})
+		})
+
+		Convey("When the user follows themselves", func() {
+			res := send(http.MethodPost, "/user/users/"+follower.ID+"/following/"+follower.ID)
+
+			Convey("Then status code should be 400", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+		})
+	})
+}
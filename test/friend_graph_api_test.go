@@ -0,0 +1,173 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFriendGraph(t *testing.T) {
+	Convey("Given a chain of friends where the user and their friend have a friend in common", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		newUser := func(id, name string, friendIDs []string) model.User {
+			return model.User{
+				ID:          id,
+				Name:        name,
+				Surname:     "Bond",
+				Email:       id + "@gmail.com",
+				Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+				FriendIDs:   friendIDs,
+				UserType:    "user",
+				IsActivated: true,
+			}
+		}
+		user := newUser("111111", "James", []string{"222222", "555555"})
+		friend := newUser("222222", "Mehmet", []string{"111111", "333333", "555555"})
+		friendOfFriend := newUser("333333", "Ahmet", []string{"222222", "444444"})
+		thirdDegreeUser := newUser("444444", "Ali", []string{"333333"})
+		commonFriend := newUser("555555", "Veli", []string{"111111", "222222"})
+		for _, registeredUser := range []model.User{user, friend, friendOfFriend, thirdDegreeUser, commonFriend} {
+			testRepository.RegisterUser(registeredUser)
+		}
+
+		get := func(path string) *http.Response {
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			req.Header.Add("Authorization", GetBearerToken(user.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			return res
+		}
+
+		Convey("When the user requests the mutual friends with their friend", func() {
+			res := get("/user/users/" + friend.ID + "/mutualFriends")
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			Convey("Then the common friend should return", func() {
+				actualResult := []model.PublicUserView{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult, ShouldHaveLength, 1)
+				So(actualResult[0].ID, ShouldEqual, commonFriend.ID)
+			})
+		})
+
+		Convey("When the user requests the profile of their friend", func() {
+			res := get("/user/users/" + friend.ID)
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			Convey("Then the friend counts should return", func() {
+				actualResult := model.ProfileUserView{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult.FriendCount, ShouldEqual, 3)
+				So(actualResult.MutualFriendCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the user requests their connections", func() {
+			res := get("/user/users/" + user.ID + "/connections")
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			Convey("Then friends and friends of friends should return with their degree", func() {
+				actualResult := []model.Connection{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult, ShouldHaveLength, 3)
+				So(actualResult[0].User.ID, ShouldEqual, friend.ID)
+				So(actualResult[0].Degree, ShouldEqual, 1)
+				So(actualResult[1].User.ID, ShouldEqual, commonFriend.ID)
+				So(actualResult[1].Degree, ShouldEqual, 1)
+				So(actualResult[2].User.ID, ShouldEqual, friendOfFriend.ID)
+				So(actualResult[2].Degree, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the user requests the degree of separation to a user three friendships away", func() {
+			Convey("Then status code should be 404 within the default depth", func() {
+				res := get("/user/users/" + user.ID + "/connections/" + thirdDegreeUser.ID)
+				So(res.StatusCode, ShouldEqual, fiber.StatusNotFound)
+			})
+
+			Convey("Then the degree should return within a depth of three", func() {
+				res := get("/user/users/" + user.ID + "/connections/" + thirdDegreeUser.ID + "?depth=3")
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.Connection{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult.User.ID, ShouldEqual, thirdDegreeUser.ID)
+				So(actualResult.Degree, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the friend of their friend is banned", func() {
+			bannedUser, err := testRepository.GetUser(friendOfFriend.ID)
+			So(err, ShouldBeNil)
+			bannedUser.IsBanned = true
+			_, err = testRepository.UpdateUser(bannedUser.ID, *bannedUser)
+			So(err, ShouldBeNil)
+
+			Convey("Then the banned user should not be returned nor walked through", func() {
+				res := get("/user/users/" + user.ID + "/connections?depth=3")
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := []model.Connection{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult, ShouldHaveLength, 2)
+				So(actualResult[0].User.ID, ShouldEqual, friend.ID)
+				So(actualResult[1].User.ID, ShouldEqual, commonFriend.ID)
+
+				res = get("/user/users/" + user.ID + "/connections/" + thirdDegreeUser.ID + "?depth=3")
+				So(res.StatusCode, ShouldEqual, fiber.StatusNotFound)
+			})
+		})
+
+		Convey("When the users were saved before users could be banned", func() {
+			for _, userID := range []string{friend.ID, friendOfFriend.ID, commonFriend.ID} {
+				UnsetUserField(testRepository, userID, "isBanned")
+			}
+
+			Convey("Then they should still be returned and walked through", func() {
+				res := get("/user/users/" + user.ID + "/connections")
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := []model.Connection{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult, ShouldHaveLength, 3)
+				So(actualResult[2].User.ID, ShouldEqual, friendOfFriend.ID)
+			})
+		})
+
+		Convey("When the user requests the connections of another user", func() {
+			res := get("/user/users/" + friend.ID + "/connections")
+
+			Convey("Then status code should be 403", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+		})
+	})
+}
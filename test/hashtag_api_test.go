@@ -0,0 +1,219 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHashtagsAndMentions(t *testing.T) {
+	Convey("Given two users with usernames", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		author := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Username:    "james.bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		reader := model.User{
+			ID:          "123123",
+			Name:        "Mehmet",
+			Surname:     "Bond",
+			Username:    "mehmet",
+			Email:       "test1@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(author)
+		testRepository.RegisterUser(reader)
+
+		Convey("When the author creates a public post with hashtags and a mention", func() {
+			postDTO := model.PostDTO{
+				Description: "Back in #London with @Mehmet. #Spy #london",
+				Audience:    model.AudiencePublic,
+			}
+			reqBody, err := json.Marshal(postDTO)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/posts", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken(author.ID, "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+			post := model.Post{}
+			httpResponseBody, _ := ioutil.ReadAll(res.Body)
+			So(json.Unmarshal(httpResponseBody, &post), ShouldBeNil)
+
+			Convey("Then the hashtags and the mentioned user should be stored", func() {
+				So(post.Hashtags, ShouldResemble, []string{"london", "spy"})
+				So(post.MentionedUserIDs, ShouldResemble, []string{reader.ID})
+			})
+
+			Convey("Then the mentioned user should be notified", func() {
+				req, _ := http.NewRequest(http.MethodGet, "/user/notifications", nil)
+				req.Header.Add("Authorization", GetBearerToken(reader.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.NotificationsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult.Notifications, ShouldHaveLength, 1)
+				So(actualResult.Notifications[0].Type, ShouldEqual, model.NotificationMention)
+				So(actualResult.Notifications[0].PostID, ShouldEqual, post.ID)
+				So(actualResult.Notifications[0].Actor.Username, ShouldEqual, author.Username)
+				So(actualResult.Notifications[0].IsRead, ShouldBeFalse)
+			})
+
+			Convey("Then editing the post should not notify the mentioned user again", func() {
+				updatePostDTO := model.UpdatePostDTO{Description: "Back in #Paris with @mehmet"}
+				reqBody, err := json.Marshal(updatePostDTO)
+				So(err, ShouldBeNil)
+
+				req, _ := http.NewRequest(http.MethodPatch, "/user/posts/"+post.ID, bytes.NewReader(reqBody))
+				req.Header.Add("Content-Type", "application/json")
+				req.Header.Add("Authorization", GetBearerToken(author.ID, "user"))
+				req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				notifications, err := testRepository.GetNotifications(reader.ID, nil, 10)
+				So(err, ShouldBeNil)
+				So(notifications, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the author mentions the reader in a friends only post", func() {
+			postDTO := model.PostDTO{
+				Description: "Secret plans @mehmet #mission",
+				Audience:    model.AudienceFriends,
+			}
+			reqBody, err := json.Marshal(postDTO)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/posts", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken(author.ID, "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+			Convey("Then the reader should not be notified nor see the post in the hashtag feed", func() {
+				notifications, err := testRepository.GetNotifications(reader.ID, nil, 10)
+				So(err, ShouldBeNil)
+				So(notifications, ShouldBeEmpty)
+
+				req, _ := http.NewRequest(http.MethodGet, "/user/hashtags/mission", nil)
+				req.Header.Add("Authorization", GetBearerToken(reader.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.PostsCursorResponse{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+				So(actualResult.Posts, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the reader tries to take the author's username", func() {
+			updateUserDTO := model.UpdateUserDTO{Username: "James.Bond"}
+			reqBody, err := json.Marshal(updateUserDTO)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodPatch, "/user/users/"+reader.ID, bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken(reader.ID, "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 409", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusConflict)
+			})
+		})
+	})
+}
+
+func TestTrendingHashtags(t *testing.T) {
+	Convey("Given recent and old posts with hashtags", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		posts := []model.Post{
+			{Hashtags: []string{"golang", "mongo"}, Audience: model.AudiencePublic, CreatedAt: time.Now().UTC().Add(-time.Hour)},
+			{Hashtags: []string{"golang"}, Audience: model.AudiencePublic, CreatedAt: time.Now().UTC().Add(-2 * time.Hour)},
+			{Hashtags: []string{"golang", "secret"}, Audience: model.AudienceFriends, IsPrivate: true, CreatedAt: time.Now().UTC()},
+			{Hashtags: []string{"mongo", "old"}, Audience: model.AudiencePublic, CreatedAt: time.Now().UTC().Add(-72 * time.Hour)},
+		}
+		for _, post := range posts {
+			post.ID = utils.GenerateUUID(8)
+			post.UserID = "3c0bbdae"
+			post.UpdatedAt = post.CreatedAt
+			testRepository.CreatePost(post)
+		}
+
+		Convey("When user gets the trending hashtags of the last day", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/user/hashtags?hours=24", nil)
+			req.Header.Add("Authorization", GetBearerToken("123123", "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then only recent public hashtags should be counted", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				var actualResult []model.TrendingHashtag
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+
+				So(actualResult, ShouldResemble, []model.TrendingHashtag{
+					{Hashtag: "golang", Count: 2},
+					{Hashtag: "mongo", Count: 1},
+				})
+			})
+		})
+	})
+}
@@ -1,19 +1,275 @@
 package test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/model"
 	"github.com/anilaydinn/socium-be/repository"
+	"github.com/anilaydinn/socium-be/utils"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
+// GetCleanTestRepository drops the test database before connecting again, so the indexes and
+// views are set up as in production.
 func GetCleanTestRepository() *repository.Repository {
-	repository := repository.NewRepository("mongodb://localhost:27017")
+	previousRepository := repository.NewRepository("mongodb://localhost:27017")
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	testDB := repository.MongoClient.Database("socium")
+	testDB := previousRepository.MongoClient.Database("socium")
 	testDB.Drop(ctx)
+	previousRepository.MongoClient.Disconnect(ctx)
 
-	return repository
+	return repository.NewRepository("mongodb://localhost:27017")
+}
+
+// UnsetUserField removes a field from the stored user, as for users saved before the field
+// was added.
+func UnsetUserField(testRepository *repository.Repository, userID, field string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	users := testRepository.MongoClient.Database("socium").Collection("users")
+	users.UpdateOne(ctx, bson.M{"id": userID}, bson.M{"$unset": bson.M{field: ""}})
+}
+
+func GetBearerToken(userID, userType string) string {
+	token, _, _ := auth.GenerateAccessToken(model.User{
+		ID:       userID,
+		UserType: userType,
+	})
+
+	return "Bearer " + token
+}
+
+// MockOIDCIssuer is a local OpenID Connect issuer that approves every authorization
+// request and checks the PKCE verifier when the code is exchanged.
+type MockOIDCIssuer struct {
+	Server        *httptest.Server
+	Subject       string
+	Email         string
+	EmailVerified bool
+
+	mutex          sync.Mutex
+	codeChallenges map[string]string
+}
+
+func NewMockOIDCIssuer(subject, email string, emailVerified bool) *MockOIDCIssuer {
+	issuer := &MockOIDCIssuer{
+		Subject:        subject,
+		Email:          email,
+		EmailVerified:  emailVerified,
+		codeChallenges: map[string]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.Server.URL,
+			"authorization_endpoint": issuer.Server.URL + "/authorize",
+			"token_endpoint":         issuer.Server.URL + "/token",
+			"userinfo_endpoint":      issuer.Server.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("code_challenge_method") != "S256" {
+			http.Error(w, "unsupported code challenge method", http.StatusBadRequest)
+			return
+		}
+
+		code := utils.GenerateSecureToken(8)
+		issuer.mutex.Lock()
+		issuer.codeChallenges[code] = query.Get("code_challenge")
+		issuer.mutex.Unlock()
+
+		http.Redirect(w, r, query.Get("redirect_uri")+"?code="+code+"&state="+url.QueryEscape(query.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		issuer.mutex.Lock()
+		codeChallenge, ok := issuer.codeChallenges[r.PostForm.Get("code")]
+		delete(issuer.codeChallenges, r.PostForm.Get("code"))
+		issuer.mutex.Unlock()
+
+		if !ok || auth.PKCEChallenge(r.PostForm.Get("code_verifier")) != codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "mock-access-token", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mock-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":            issuer.Subject,
+			"email":          issuer.Email,
+			"email_verified": issuer.EmailVerified,
+			"given_name":     "Test Name",
+			"family_name":    "Test Surname",
+		})
+	})
+
+	issuer.Server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+// Authorize follows the authorization URL and returns the callback URL the issuer redirects to.
+func (issuer *MockOIDCIssuer) Authorize(authURL string) (string, error) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	return res.Header.Get("Location"), nil
+}
+
+// MockS3Server is a local stand-in for an S3 compatible service. It keeps objects in memory
+// and rejects requests that are not signed with Signature Version 4.
+type MockS3Server struct {
+	Server *httptest.Server
+
+	mutex        sync.Mutex
+	objects      map[string][]byte
+	contentTypes map[string]string
+}
+
+func NewMockS3Server() *MockS3Server {
+	server := &MockS3Server{
+		objects:      map[string][]byte{},
+		contentTypes: map[string]string{},
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		payloadHash := sha256.Sum256(body)
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=") ||
+			r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(payloadHash[:]) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			server.objects[r.URL.Path] = body
+			server.contentTypes[r.URL.Path] = r.Header.Get("Content-Type")
+		case http.MethodGet:
+			object, ok := server.objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", server.contentTypes[r.URL.Path])
+			w.Write(object)
+		case http.MethodDelete:
+			delete(server.objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+
+	return server
+}
+
+// GetObject returns the object stored under path, "/<bucket>/<key>".
+func (server *MockS3Server) GetObject(path string) ([]byte, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	object, ok := server.objects[path]
+	return object, ok
+}
+
+// GetTestJPEG returns a JPEG image with an EXIF orientation tag when orientation is set.
+func GetTestJPEG(width, height, orientation int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 128, A: 255})
+		}
+	}
+
+	var encoded bytes.Buffer
+	jpeg.Encode(&encoded, img, nil)
+	data := encoded.Bytes()
+	if orientation == 0 {
+		return data
+	}
+
+	// Big endian TIFF data with a single IFD entry: tag 0x0112, type SHORT, count 1.
+	exif := []byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01")
+	exif = append(exif, 0x00, byte(orientation), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+	segment := []byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}
+	segment = append(segment, exif...)
+
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+// GetTestGIF returns an animated GIF with the given number of single color frames.
+func GetTestGIF(width, height, frames int) []byte {
+	frame := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black, color.White})
+
+	animation := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+
+	var encoded bytes.Buffer
+	gif.EncodeAll(&encoded, animation)
+
+	return encoded.Bytes()
+}
+
+// GetMultipartBody returns a multipart form with the data in a file field, and its content type.
+func GetMultipartBody(field, filename string, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile(field, filename)
+	part.Write(data)
+	writer.Close()
+
+	return body, writer.FormDataContentType()
+}
+
+func GetPublicUserView(user model.User) *model.PublicUserView {
+	userView := model.NewPublicUserView(user)
+	return &userView
 }
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/utils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCleanOrphans(t *testing.T) {
+	Convey("Given orphaned posts, comments and references", t, func() {
+		testRepository := GetCleanTestRepository()
+		service := service.NewService(testRepository)
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			FriendIDs:   []string{"deleted1"},
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		post := model.Post{
+			ID:         utils.GenerateUUID(8),
+			UserID:     registeredUser.ID,
+			CommentIDs: []string{"missing1"},
+			CreatedAt:  time.Now().UTC().Round(time.Second),
+			UpdatedAt:  time.Now().UTC().Round(time.Second),
+		}
+		orphanPost := model.Post{
+			ID:        utils.GenerateUUID(8),
+			UserID:    "deleted1",
+			CreatedAt: time.Now().UTC().Round(time.Second),
+			UpdatedAt: time.Now().UTC().Round(time.Second),
+		}
+		expiredDeletedAt := time.Now().UTC().Add(-31 * 24 * time.Hour).Round(time.Second)
+		expiredPost := model.Post{
+			ID:        utils.GenerateUUID(8),
+			UserID:    registeredUser.ID,
+			CreatedAt: time.Now().UTC().Add(-40 * 24 * time.Hour).Round(time.Second),
+			UpdatedAt: time.Now().UTC().Add(-40 * 24 * time.Hour).Round(time.Second),
+			DeletedAt: &expiredDeletedAt,
+		}
+		testRepository.CreatePost(post)
+		testRepository.CreatePost(orphanPost)
+		testRepository.CreatePost(expiredPost)
+
+		for _, userID := range []string{"deleted1", registeredUser.ID} {
+			testRepository.SetReaction(model.Reaction{
+				ID:         utils.GenerateUUID(8),
+				UserID:     userID,
+				TargetType: model.ReactionTargetPost,
+				TargetID:   post.ID,
+				PostID:     post.ID,
+				Type:       model.ReactionLike,
+				CreatedAt:  time.Now().UTC().Round(time.Second),
+			})
+		}
+
+		testRepository.CreateFriendRequest(model.Friendship{
+			ID:          utils.GenerateUUID(8),
+			RequesterID: "deleted1",
+			AddresseeID: registeredUser.ID,
+			Status:      model.FriendshipPending,
+			CreatedAt:   time.Now().UTC().Round(time.Second),
+			UpdatedAt:   time.Now().UTC().Round(time.Second),
+		})
+
+		testRepository.AddComment(model.Comment{
+			ID:        utils.GenerateUUID(8),
+			UserID:    registeredUser.ID,
+			PostID:    "deletedPost",
+			Content:   "Comment",
+			CreatedAt: time.Now().UTC().Round(time.Second),
+			UpdatedAt: time.Now().UTC().Round(time.Second),
+		})
+
+		Convey("When orphans are cleaned", func() {
+			report, err := service.CleanOrphans()
+			So(err, ShouldBeNil)
+
+			Convey("Then orphans should be counted", func() {
+				So(report.DeletedPosts, ShouldEqual, 2)
+				So(report.DeletedComments, ShouldEqual, 1)
+				So(report.DeletedReactions, ShouldEqual, 1)
+				So(report.DeletedFriendships, ShouldEqual, 1)
+				So(report.UpdatedPosts, ShouldEqual, 1)
+				So(report.UpdatedUsers, ShouldEqual, 1)
+			})
+
+			Convey("Then only valid data should remain", func() {
+				postCount, err := testRepository.GetPostCount()
+				So(err, ShouldBeNil)
+				So(postCount, ShouldEqual, 1)
+
+				commentCount, err := testRepository.GetCommentCount()
+				So(err, ShouldBeNil)
+				So(commentCount, ShouldEqual, 0)
+
+				updatedPost, err := testRepository.GetPost(post.ID)
+				So(err, ShouldBeNil)
+				So(updatedPost.CommentIDs, ShouldBeEmpty)
+
+				reaction, err := testRepository.GetReaction(model.ReactionTargetPost, post.ID, registeredUser.ID)
+				So(err, ShouldBeNil)
+				So(reaction, ShouldNotBeNil)
+
+				user, err := testRepository.GetUser(registeredUser.ID)
+				So(err, ShouldBeNil)
+				So(user.FriendIDs, ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,353 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/anilaydinn/socium-be/storage"
+	"github.com/anilaydinn/socium-be/utils"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUploadMedia(t *testing.T) {
+	Convey("Given a authenticated user and a local storage", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		storage.SetStorage(storage.NewLocalStorage(t.TempDir()))
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		bearerToken := GetBearerToken("3c0bbdae", "user")
+
+		Convey("When user uploads a rotated JPEG with EXIF data", func() {
+			reqBody, contentType := GetMultipartBody("file", "photo.jpg", GetTestJPEG(800, 400, 6))
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/media", reqBody)
+			req.Header.Add("Content-Type", contentType)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 201", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+			})
+
+			Convey("Then the image should be served upright without EXIF data", func() {
+				media := model.Media{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &media)
+				So(err, ShouldBeNil)
+
+				So(media.UserID, ShouldEqual, registeredUser.ID)
+				So(media.ContentType, ShouldEqual, "image/jpeg")
+				So(media.Width, ShouldEqual, 400)
+				So(media.Height, ShouldEqual, 800)
+
+				fileReq, _ := http.NewRequest(http.MethodGet, media.URL, nil)
+				fileRes, err := app.Test(fileReq, 30000)
+				So(err, ShouldBeNil)
+				So(fileRes.StatusCode, ShouldEqual, fiber.StatusOK)
+				So(fileRes.Header.Get("Content-Type"), ShouldEqual, "image/jpeg")
+
+				file, _ := ioutil.ReadAll(fileRes.Body)
+				So(bytes.Contains(file, []byte("Exif")), ShouldBeFalse)
+
+				thumbnailReq, _ := http.NewRequest(http.MethodGet, media.ThumbnailURL, nil)
+				thumbnailRes, err := app.Test(thumbnailReq, 30000)
+				So(err, ShouldBeNil)
+				So(thumbnailRes.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				thumbnail, _, err := image.DecodeConfig(thumbnailRes.Body)
+				So(err, ShouldBeNil)
+				So(thumbnail.Width, ShouldEqual, 160)
+				So(thumbnail.Height, ShouldEqual, utils.ThumbnailSize)
+			})
+
+			Convey("Then the image should only be served privately through its signed URL", func() {
+				media := model.Media{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &media)
+				So(err, ShouldBeNil)
+
+				fileReq, _ := http.NewRequest(http.MethodGet, media.URL, nil)
+				fileRes, err := app.Test(fileReq, 30000)
+				So(err, ShouldBeNil)
+				So(fileRes.StatusCode, ShouldEqual, fiber.StatusOK)
+				So(fileRes.Header.Get("Cache-Control"), ShouldStartWith, "private")
+
+				unsignedReq, _ := http.NewRequest(http.MethodGet, "/api/media/"+media.ID, nil)
+				unsignedRes, err := app.Test(unsignedReq, 30000)
+				So(err, ShouldBeNil)
+				So(unsignedRes.StatusCode, ShouldEqual, fiber.StatusForbidden)
+
+				tamperedReq, _ := http.NewRequest(http.MethodGet, media.URL+"0", nil)
+				tamperedRes, err := app.Test(tamperedReq, 30000)
+				So(err, ShouldBeNil)
+				So(tamperedRes.StatusCode, ShouldEqual, fiber.StatusForbidden)
+
+				thumbnailReq, _ := http.NewRequest(http.MethodGet, strings.Replace(media.URL, "?", "/thumbnail?", 1), nil)
+				thumbnailRes, err := app.Test(thumbnailReq, 30000)
+				So(err, ShouldBeNil)
+				So(thumbnailRes.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+		})
+
+		Convey("When an admin deletes a post with an uploaded image", func() {
+			reqBody, contentType := GetMultipartBody("file", "photo.jpg", GetTestJPEG(200, 100, 0))
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/media", reqBody)
+			req.Header.Add("Content-Type", contentType)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+			media := model.Media{}
+			httpResponseBody, _ := ioutil.ReadAll(res.Body)
+			So(json.Unmarshal(httpResponseBody, &media), ShouldBeNil)
+			storedMedia, err := testRepository.GetMedia(media.ID)
+			So(err, ShouldBeNil)
+
+			admin := model.User{
+				ID:          "4dm1n000",
+				Name:        "Admin",
+				Surname:     "Bond",
+				Email:       "admin@gmail.com",
+				Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+				UserType:    "admin",
+				IsActivated: true,
+			}
+			testRepository.RegisterUser(admin)
+
+			post := model.Post{
+				ID:        utils.GenerateUUID(8),
+				UserID:    registeredUser.ID,
+				Image:     media.ID,
+				Media:     []model.PostMedia{{MediaID: media.ID}},
+				Audience:  model.AudiencePublic,
+				CreatedAt: time.Now().UTC().Round(time.Second),
+				UpdatedAt: time.Now().UTC().Round(time.Second),
+			}
+			testRepository.CreatePost(post)
+
+			req, _ = http.NewRequest(http.MethodDelete, "/admin/users/"+registeredUser.ID+"/posts/"+post.ID, nil)
+			req.Header.Add("Authorization", GetBearerToken(admin.ID, "admin"))
+
+			res, err = app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+			Convey("Then the image should no longer be served through its signed URLs", func() {
+				_, err := testRepository.GetMedia(media.ID)
+				So(err, ShouldNotBeNil)
+
+				for _, url := range []string{media.URL, media.ThumbnailURL} {
+					fileReq, _ := http.NewRequest(http.MethodGet, url, nil)
+					fileRes, err := app.Test(fileReq, 30000)
+					So(err, ShouldBeNil)
+					So(fileRes.StatusCode, ShouldEqual, fiber.StatusNotFound)
+				}
+
+				for _, key := range []string{storedMedia.Key, storedMedia.ThumbnailKey} {
+					_, _, err := storage.GetStorage().Get(key)
+					So(err, ShouldNotBeNil)
+				}
+			})
+		})
+
+		Convey("When user uploads a file that is not an image", func() {
+			reqBody, contentType := GetMultipartBody("file", "notes.txt", []byte("Hello"))
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/media", reqBody)
+			req.Header.Add("Content-Type", contentType)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 415", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusUnsupportedMediaType)
+			})
+		})
+
+		Convey("When user uploads a GIF whose frames together have too many pixels", func() {
+			reqBody, contentType := GetMultipartBody("file", "animation.gif", GetTestGIF(2000, 2000, 11))
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/media", reqBody)
+			req.Header.Add("Content-Type", contentType)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 413", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusRequestEntityTooLarge)
+			})
+		})
+
+		Convey("When user uploads an image over the size limit", func() {
+			os.Setenv("MAX_UPLOAD_SIZE", "1024")
+			defer os.Unsetenv("MAX_UPLOAD_SIZE")
+
+			reqBody, contentType := GetMultipartBody("file", "photo.jpg", GetTestJPEG(800, 400, 0))
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/media", reqBody)
+			req.Header.Add("Content-Type", contentType)
+			req.Header.Add("Authorization", bearerToken)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 413", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusRequestEntityTooLarge)
+			})
+		})
+	})
+}
+
+func TestUploadMediaToS3(t *testing.T) {
+	Convey("Given a authenticated user and a S3 storage", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		s3Server := NewMockS3Server()
+		defer s3Server.Server.Close()
+		storage.SetStorage(storage.NewS3Storage(s3Server.Server.URL, "socium-media", "us-east-1", "access-key", "secret-key"))
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		Convey("When user uploads an image", func() {
+			reqBody, contentType := GetMultipartBody("file", "photo.jpg", GetTestJPEG(200, 100, 0))
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/media", reqBody)
+			req.Header.Add("Content-Type", contentType)
+			req.Header.Add("Authorization", GetBearerToken("3c0bbdae", "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then the image should be stored in the bucket and served back", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusCreated)
+
+				media := model.Media{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &media)
+				So(err, ShouldBeNil)
+
+				object, ok := s3Server.GetObject("/socium-media/media/" + media.ID + "/original")
+				So(ok, ShouldBeTrue)
+
+				fileReq, _ := http.NewRequest(http.MethodGet, media.URL, nil)
+				fileRes, err := app.Test(fileReq, 30000)
+				So(err, ShouldBeNil)
+				So(fileRes.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				file, _ := ioutil.ReadAll(fileRes.Body)
+				So(file, ShouldResemble, object)
+			})
+		})
+	})
+}
+
+func TestCreatePostWithAnotherUsersMedia(t *testing.T) {
+	Convey("Given media uploaded by another user", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Name:        "James",
+			Surname:     "Bond",
+			Email:       "test@gmail.com",
+			Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		media := model.Media{
+			ID:          utils.GenerateUUID(0),
+			UserID:      "123123",
+			ContentType: "image/jpeg",
+			CreatedAt:   time.Now().UTC().Round(time.Second),
+		}
+		testRepository.CreateMedia(media)
+
+		Convey("When user creates a post with that media", func() {
+			postDTO := model.PostDTO{
+				UserID:      registeredUser.ID,
+				Description: "Post description",
+				Image:       media.ID,
+			}
+			reqBody, err := json.Marshal(postDTO)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/user/posts", bytes.NewReader(reqBody))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("Authorization", GetBearerToken("3c0bbdae", "user"))
+			req.Header.Set("Content-Length", strconv.Itoa(len(reqBody)))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 400", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+
+			Convey("Then post should not be created", func() {
+				postCount, err := testRepository.GetPostCount()
+				So(err, ShouldBeNil)
+				So(postCount, ShouldEqual, 0)
+			})
+		})
+	})
+}
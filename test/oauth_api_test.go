@@ -0,0 +1,220 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/anilaydinn/socium-be/auth"
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/gofiber/fiber/v2"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOAuthLogin(t *testing.T) {
+	Convey("Given a registered user and a mock OIDC issuer", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		registeredUser := model.User{
+			ID:          "3c0bbdae",
+			Email:       "test@gmail.com",
+			Name:        "Test Name",
+			Surname:     "Test Surname",
+			Password:    "$2a$10$WCtghenC3N2Kg6ZjcoN/6O7fEJgTz5UzN65JoCGfxabqfEGJrxdBu",
+			UserType:    "user",
+			IsActivated: true,
+		}
+		testRepository.RegisterUser(registeredUser)
+
+		oldToken, _, err := service.LoginUser(model.UserCredentialsDTO{
+			Email:    "test@gmail.com",
+			Password: "123123",
+		}, "0.0.0.0")
+		So(err, ShouldBeNil)
+
+		issuer := NewMockOIDCIssuer("mock-subject", "test@gmail.com", true)
+		defer issuer.Server.Close()
+
+		provider, err := auth.NewOIDCProvider("mock", issuer.Server.URL, "client-id", "client-secret", "http://localhost/api/oauth/mock/callback")
+		So(err, ShouldBeNil)
+		auth.RegisterOAuthProvider(provider)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/oauth/mock", nil)
+		res, err := app.Test(req, 30000)
+		So(err, ShouldBeNil)
+		So(res.StatusCode, ShouldEqual, fiber.StatusFound)
+
+		stateCookie := res.Header.Get("Set-Cookie")
+		So(stateCookie, ShouldStartWith, "oauth-state=")
+
+		callbackURL, err := issuer.Authorize(res.Header.Get("Location"))
+		So(err, ShouldBeNil)
+		callback, err := url.Parse(callbackURL)
+		So(err, ShouldBeNil)
+
+		Convey("When callback request sent with the state cookie", func() {
+			req, _ := http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+			req.Header.Add("Cookie", stateCookie)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then user token should be returned", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+				actualResult := model.Token{}
+				httpResponseBody, _ := ioutil.ReadAll(res.Body)
+				err := json.Unmarshal(httpResponseBody, &actualResult)
+				So(err, ShouldBeNil)
+				So(len(actualResult.Token), ShouldBeGreaterThan, 0)
+			})
+
+			Convey("Then external identity should be linked to the existing user", func() {
+				user, err := testRepository.GetUserByExternalIdentity("mock", "mock-subject")
+				So(err, ShouldBeNil)
+				So(user.ID, ShouldEqual, registeredUser.ID)
+			})
+
+			Convey("Then the user should still log in with their password", func() {
+				_, _, err := service.LoginUser(model.UserCredentialsDTO{
+					Email:    registeredUser.Email,
+					Password: "123123",
+				}, "0.0.0.0")
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Then sessions from before the link should be rejected", func() {
+				req, _ := http.NewRequest(http.MethodGet, "/user/users/"+registeredUser.ID+"/friends", nil)
+				req.Header.Add("Authorization", "Bearer "+oldToken.Token)
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusUnauthorized)
+
+				_, err = service.RefreshToken(model.RefreshTokenDTO{RefreshToken: oldToken.RefreshToken})
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then callback should not be replayable", func() {
+				req, _ := http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+				req.Header.Add("Cookie", stateCookie)
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+		})
+
+		Convey("When callback request sent without the state cookie", func() {
+			req, _ := http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 400", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusBadRequest)
+			})
+		})
+
+		Convey("When registered user is not activated", func() {
+			user, err := testRepository.GetUser(registeredUser.ID)
+			So(err, ShouldBeNil)
+			user.IsActivated = false
+			_, err = testRepository.UpdateUser(user.ID, *user)
+			So(err, ShouldBeNil)
+
+			req, _ := http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+			req.Header.Add("Cookie", stateCookie)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 403", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+
+			Convey("Then external identity should not be linked", func() {
+				_, err := testRepository.GetUserByExternalIdentity("mock", "mock-subject")
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When issuer does not verify the email", func() {
+			issuer.EmailVerified = false
+
+			req, _ := http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+			req.Header.Add("Cookie", stateCookie)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 403", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+		})
+	})
+}
+
+func TestOAuthLoginNewUser(t *testing.T) {
+	Convey("Given a mock OIDC issuer with an unknown user", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		issuer := NewMockOIDCIssuer("new-subject", "new@gmail.com", true)
+		defer issuer.Server.Close()
+
+		provider, err := auth.NewOIDCProvider("mock", issuer.Server.URL, "client-id", "client-secret", "http://localhost/api/oauth/mock/callback")
+		So(err, ShouldBeNil)
+		auth.RegisterOAuthProvider(provider)
+
+		Convey("When user completes the login", func() {
+			authURL, state, err := service.StartOAuthLogin("mock")
+			So(err, ShouldBeNil)
+
+			callbackURL, err := issuer.Authorize(authURL)
+			So(err, ShouldBeNil)
+			callback, err := url.Parse(callbackURL)
+			So(err, ShouldBeNil)
+
+			token, _, err := service.CompleteOAuthLogin("mock", callback.Query().Get("code"), callback.Query().Get("state"), state)
+
+			Convey("Then new activated user should be registered", func() {
+				So(err, ShouldBeNil)
+				So(len(token.Token), ShouldBeGreaterThan, 0)
+
+				user, err := testRepository.GetUserByEmail("new@gmail.com")
+				So(err, ShouldBeNil)
+				So(user.IsActivated, ShouldBeTrue)
+				So(user.UserType, ShouldEqual, model.RoleUser)
+				So(user.ExternalIdentities, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When unknown provider requested", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/api/oauth/unknown", nil)
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 404", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusNotFound)
+			})
+		})
+	})
+}
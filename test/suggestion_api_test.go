@@ -0,0 +1,152 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anilaydinn/socium-be/controller"
+	"github.com/anilaydinn/socium-be/middleware"
+	"github.com/anilaydinn/socium-be/model"
+	"github.com/anilaydinn/socium-be/service"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetUserSuggestions(t *testing.T) {
+	Convey("Given a user with a friend, friends of the friend, a neighbour, a user posting the same hashtag and a user interacting with their posts", t, func() {
+		app := fiber.New()
+		testRepository := GetCleanTestRepository()
+		middleware.SetupMiddleWare(app, *testRepository)
+		service := service.NewService(testRepository)
+		api := controller.NewAPI(&service)
+
+		api.SetupApp(app)
+
+		newUser := func(id, name string, friendIDs []string) model.User {
+			return model.User{
+				ID:          id,
+				Name:        name,
+				Surname:     "Bond",
+				Email:       id + "@gmail.com",
+				Password:    "$2a$10$08qe8bXis2qObLNyEJfzpePCnqSJRyUXIa//ALLJw9l8q5gOTJljq",
+				FriendIDs:   friendIDs,
+				UserType:    "user",
+				IsActivated: true,
+			}
+		}
+		user := newUser("3c0bbdae", "James", []string{"123123"})
+		user.Latitude, user.Longitude = 41.0082, 28.9784
+		friend := newUser("123123", "Mehmet", []string{"3c0bbdae", "321321", "456456"})
+		friendOfFriend := newUser("321321", "Ahmet", []string{"123123"})
+		requestedFriendOfFriend := newUser("456456", "Ali", []string{"123123"})
+		neighbour := newUser("654654", "Veli", nil)
+		neighbour.Latitude, neighbour.Longitude = 41.0082, 28.9784
+		hashtagUser := newUser("789789", "Ayse", nil)
+		interactingUser := newUser("987987", "Fatma", nil)
+		for _, registeredUser := range []model.User{user, friend, friendOfFriend, requestedFriendOfFriend, neighbour, hashtagUser, interactingUser} {
+			testRepository.RegisterUser(registeredUser)
+		}
+
+		now := time.Now().UTC().Round(time.Second)
+		testRepository.CreateFriendRequest(model.Friendship{
+			ID:          "fr13nd01",
+			RequesterID: user.ID,
+			AddresseeID: requestedFriendOfFriend.ID,
+			Status:      model.FriendshipPending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		for _, post := range []model.Post{
+			{ID: "p0st0001", UserID: user.ID, Hashtags: []string{"london"}},
+			{ID: "p0st0002", UserID: hashtagUser.ID, Hashtags: []string{"london"}},
+		} {
+			post.Audience = model.AudiencePublic
+			post.CreatedAt = now
+			post.UpdatedAt = now
+			testRepository.CreatePost(post)
+		}
+		testRepository.AddComment(model.Comment{
+			ID:        "c0mm3nt1",
+			UserID:    interactingUser.ID,
+			PostID:    "p0st0001",
+			Content:   "Comment",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		testRepository.SetReaction(model.Reaction{
+			ID:         "r3act1on",
+			UserID:     interactingUser.ID,
+			TargetType: model.ReactionTargetPost,
+			TargetID:   "p0st0001",
+			PostID:     "p0st0001",
+			Type:       model.ReactionLike,
+			CreatedAt:  now,
+		})
+
+		getSuggestions := func() []model.Suggestion {
+			req, _ := http.NewRequest(http.MethodGet, "/user/users/"+user.ID+"/suggestions", nil)
+			req.Header.Add("Authorization", GetBearerToken(user.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+			So(res.StatusCode, ShouldEqual, fiber.StatusOK)
+
+			actualResult := []model.Suggestion{}
+			httpResponseBody, _ := ioutil.ReadAll(res.Body)
+			So(json.Unmarshal(httpResponseBody, &actualResult), ShouldBeNil)
+			return actualResult
+		}
+
+		Convey("When the suggestions are refreshed", func() {
+			refreshedUsers, err := service.RefreshSuggestions()
+			So(err, ShouldBeNil)
+			So(refreshedUsers, ShouldEqual, 7)
+
+			Convey("Then they should be ranked by interactions, mutual friends, closeness and shared hashtags", func() {
+				suggestions := getSuggestions()
+				So(suggestions, ShouldHaveLength, 4)
+
+				So(suggestions[0].User.ID, ShouldEqual, interactingUser.ID)
+				So(suggestions[0].InteractionCount, ShouldEqual, 2)
+				So(suggestions[1].User.ID, ShouldEqual, friendOfFriend.ID)
+				So(suggestions[1].MutualFriendCount, ShouldEqual, 1)
+				So(suggestions[2].User.ID, ShouldEqual, neighbour.ID)
+				So(suggestions[2].IsNearby, ShouldBeTrue)
+				So(suggestions[3].User.ID, ShouldEqual, hashtagUser.ID)
+				So(suggestions[3].SharedHashtagCount, ShouldEqual, 1)
+			})
+
+			Convey("When the user blocks a suggested user", func() {
+				req, _ := http.NewRequest(http.MethodPost, "/user/users/"+user.ID+"/blockedUsers/"+neighbour.ID, nil)
+				req.Header.Add("Authorization", GetBearerToken(user.ID, "user"))
+
+				res, err := app.Test(req, 30000)
+				So(err, ShouldBeNil)
+				So(res.StatusCode, ShouldEqual, fiber.StatusNoContent)
+
+				Convey("Then the blocked user should no longer be suggested", func() {
+					suggestions := getSuggestions()
+					So(suggestions, ShouldHaveLength, 3)
+					So(suggestions[0].User.ID, ShouldEqual, interactingUser.ID)
+					So(suggestions[1].User.ID, ShouldEqual, friendOfFriend.ID)
+					So(suggestions[2].User.ID, ShouldEqual, hashtagUser.ID)
+				})
+			})
+		})
+
+		Convey("When user requests the suggestions of another user", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/user/users/"+friend.ID+"/suggestions", nil)
+			req.Header.Add("Authorization", GetBearerToken(user.ID, "user"))
+
+			res, err := app.Test(req, 30000)
+			So(err, ShouldBeNil)
+
+			Convey("Then status code should be 403", func() {
+				So(res.StatusCode, ShouldEqual, fiber.StatusForbidden)
+			})
+		})
+	})
+}
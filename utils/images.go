@@ -0,0 +1,350 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/anilaydinn/socium-be/errors"
+)
+
+const (
+	// MaxImagePixels rejects images that are small files but would take too much memory to decode.
+	// The frames of a GIF count together, since every frame is decoded.
+	MaxImagePixels = 40000000
+	ThumbnailSize  = 320
+	jpegQuality    = 90
+)
+
+// ProcessedImage is an uploaded image re-encoded without its metadata, with a thumbnail
+// that fits in ThumbnailSize x ThumbnailSize.
+type ProcessedImage struct {
+	Data                 []byte
+	ContentType          string
+	Width                int
+	Height               int
+	Thumbnail            []byte
+	ThumbnailContentType string
+}
+
+// ProcessImage checks that data is a JPEG, PNG or GIF image and re-encodes it, which drops
+// EXIF and other metadata. JPEG images are rotated upright first, since their EXIF
+// orientation is lost with the metadata.
+func ProcessImage(data []byte) (*ProcessedImage, error) {
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/gif" {
+		return nil, errors.UnsupportedMediaType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.UnsupportedMediaType
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, errors.MediaTooLarge
+	}
+	if contentType == "image/gif" {
+		pixels, ok := gifFramePixels(data)
+		if !ok {
+			return nil, errors.UnsupportedMediaType
+		}
+		if pixels > MaxImagePixels {
+			return nil, errors.MediaTooLarge
+		}
+	}
+
+	var img image.Image
+	var encoded bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.UnsupportedMediaType
+		}
+		img = applyOrientation(img, jpegOrientation(data))
+		err = jpeg.Encode(&encoded, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.UnsupportedMediaType
+		}
+		err = png.Encode(&encoded, img)
+	case "image/gif":
+		animation, decodeErr := gif.DecodeAll(bytes.NewReader(data))
+		if decodeErr != nil || len(animation.Image) == 0 {
+			return nil, errors.UnsupportedMediaType
+		}
+		img = animation.Image[0]
+		err = gif.EncodeAll(&encoded, animation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail := ResizeImage(img, ThumbnailSize)
+	thumbnailContentType := "image/png"
+	var encodedThumbnail bytes.Buffer
+	if contentType == "image/jpeg" {
+		thumbnailContentType = "image/jpeg"
+		err = jpeg.Encode(&encodedThumbnail, thumbnail, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&encodedThumbnail, thumbnail)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProcessedImage{
+		Data:                 encoded.Bytes(),
+		ContentType:          contentType,
+		Width:                img.Bounds().Dx(),
+		Height:               img.Bounds().Dy(),
+		Thumbnail:            encodedThumbnail.Bytes(),
+		ThumbnailContentType: thumbnailContentType,
+	}, nil
+}
+
+// gifFramePixels adds up the pixels of every frame of the GIF by walking its blocks, without
+// decoding any of them. It reports false when the block structure is invalid.
+func gifFramePixels(data []byte) (int, bool) {
+	// The header and logical screen descriptor take 13 bytes, the global color table follows.
+	if len(data) < 13 {
+		return 0, false
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+
+	pixels := 0
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21:
+			// Extension: introducer, label and data sub-blocks.
+			pos = skipGIFSubBlocks(data, pos+2)
+		case 0x2C:
+			// Image descriptor: separator, position, size and flags, then the local color table,
+			// the LZW minimum code size and the image data sub-blocks.
+			if pos+10 > len(data) {
+				return 0, false
+			}
+			width := int(binary.LittleEndian.Uint16(data[pos+5:]))
+			height := int(binary.LittleEndian.Uint16(data[pos+7:]))
+			pixels += width * height
+			if pixels > MaxImagePixels {
+				return pixels, true
+			}
+
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			pos = skipGIFSubBlocks(data, pos+1)
+		case 0x3B:
+			return pixels, true
+		default:
+			return 0, false
+		}
+	}
+
+	// A missing trailer is left to the decoder.
+	return pixels, true
+}
+
+// skipGIFSubBlocks returns the position after the sub-blocks starting at pos and their terminator.
+func skipGIFSubBlocks(data []byte, pos int) int {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos
+		}
+		pos += size
+	}
+
+	return pos
+}
+
+// ResizeImage scales the image down to fit in maxSize x maxSize, keeping its aspect ratio.
+// Each pixel is the average of the source pixels it covers.
+func ResizeImage(src image.Image, maxSize int) image.Image {
+	rgba := toRGBA(src)
+	width, height := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	dstWidth, dstHeight := width, height
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			dstWidth, dstHeight = maxSize, height*maxSize/width
+		} else {
+			dstWidth, dstHeight = width*maxSize/height, maxSize
+		}
+	}
+	if dstWidth < 1 {
+		dstWidth = 1
+	}
+	if dstHeight < 1 {
+		dstHeight = 1
+	}
+	if dstWidth == width && dstHeight == height {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*height/dstHeight, (y+1)*height/dstHeight
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*width/dstWidth, (x+1)*width/dstWidth
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := sy*rgba.Stride + sx*4
+					for c := 0; c < 4; c++ {
+						sum[c] += int(rgba.Pix[i+c])
+					}
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			j := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[j+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+
+	return dst
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	return rgba
+}
+
+// applyOrientation turns the image upright according to its EXIF orientation, 1 to 8.
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	rgba := toRGBA(src)
+	width, height := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			i := y*rgba.Stride + x*4
+			j := dy*dst.Stride + dx*4
+			copy(dst.Pix[j:j+4], rgba.Pix[i:i+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, 1 when it has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// The image data starts at the start of scan marker, metadata comes before it.
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first IFD of EXIF data in TIFF format.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	MaxHashtagLength  = 100
+	MinUsernameLength = 3
+	MaxUsernameLength = 30
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.]*[a-z0-9_]$`)
+
+// ExtractHashtags returns the lower cased hashtags of text in order of appearance, without
+// duplicates. A hashtag starts after a "#" that does not follow a word character and holds
+// letters, digits and underscores, at least one of them a letter.
+func ExtractHashtags(text string) []string {
+	var hashtags []string
+	for _, word := range extractPrefixed(text, '#', isHashtagRune) {
+		hashtag := strings.ToLower(word)
+		if len([]rune(hashtag)) > MaxHashtagLength || strings.IndexFunc(hashtag, unicode.IsLetter) < 0 {
+			continue
+		}
+		if !Contains(hashtags, hashtag) {
+			hashtags = append(hashtags, hashtag)
+		}
+	}
+
+	return hashtags
+}
+
+// ExtractMentions returns the lower cased usernames mentioned with "@" in text, in order of
+// appearance and without duplicates. Email addresses are not mentions.
+func ExtractMentions(text string) []string {
+	var usernames []string
+	for _, word := range extractPrefixed(text, '@', isUsernameRune) {
+		// A dot ending a sentence is not part of the username.
+		username := strings.TrimRight(strings.ToLower(word), ".")
+		if !IsValidUsername(username) {
+			continue
+		}
+		if !Contains(usernames, username) {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// NormalizeHashtag returns the hashtag as it is stored, lower cased and without the "#".
+func NormalizeHashtag(hashtag string) string {
+	return strings.ToLower(strings.TrimPrefix(hashtag, "#"))
+}
+
+func IsValidUsername(username string) bool {
+	return len(username) >= MinUsernameLength && len(username) <= MaxUsernameLength && usernamePattern.MatchString(username)
+}
+
+// GetUsernameBase returns a username made of the ASCII letters and digits of the name and
+// surname, which may still be taken by another user.
+func GetUsernameBase(name, surname string) string {
+	var parts []string
+	for _, part := range []string{name, surname} {
+		part = strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, strings.ToLower(part))
+		if len(part) != 0 {
+			parts = append(parts, part)
+		}
+	}
+
+	base := strings.Join(parts, ".")
+	// Room is left for the suffix added when the base is taken.
+	if len(base) > MaxUsernameLength-5 {
+		base = strings.TrimRight(base[:MaxUsernameLength-5], ".")
+	}
+	if len(base) < MinUsernameLength {
+		base = "user"
+	}
+
+	return base
+}
+
+// extractPrefixed returns the words made of runes accepted by isWordRune that directly
+// follow prefix, when prefix itself does not follow a word rune.
+func extractPrefixed(text string, prefix rune, isWordRune func(rune) bool) []string {
+	var words []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != prefix || (i > 0 && isWordRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if end > i+1 {
+			words = append(words, string(runes[i+1:end]))
+		}
+		i = end - 1
+	}
+
+	return words
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func isUsernameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '.'
+}
@@ -1,15 +1,30 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
 	"math"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const MaxInt = int(^uint(0) >> 1)
 
+var (
+	jwtSecret     []byte
+	jwtSecretOnce sync.Once
+)
+
 func GenerateUUID(length int) string {
 	uuid := uuid.New().String()
 
@@ -35,6 +50,123 @@ func GetDBUrl() string {
 	return databaseUrl
 }
 
+// GetJWTSecret returns the HMAC key used to sign access tokens. When JWT_SECRET is
+// not set a random key is generated, so issued tokens do not survive a restart.
+func GetJWTSecret() []byte {
+	jwtSecretOnce.Do(func() {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			log.Println("JWT_SECRET is not set, using a random signing secret")
+			secret = GenerateSecureToken(32)
+		}
+		jwtSecret = []byte(secret)
+	})
+
+	return jwtSecret
+}
+
+func GetAccessTokenTTL() time.Duration {
+	return getDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+func GetRefreshTokenTTL() time.Duration {
+	return getDurationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// GetMediaURLTTL returns how long signed media URLs can be used at least.
+func GetMediaURLTTL() time.Duration {
+	return getDurationEnv("MEDIA_URL_TTL", time.Hour)
+}
+
+// GetMaxUploadSize returns the largest media file accepted, in bytes.
+func GetMaxUploadSize() int {
+	value, err := strconv.Atoi(os.Getenv("MAX_UPLOAD_SIZE"))
+	if err != nil || value <= 0 {
+		return 8 << 20
+	}
+
+	return value
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Println("Invalid " + key + ", using default value")
+		return defaultValue
+	}
+
+	return duration
+}
+
+// GenerateSecureToken returns a hex encoded random string built from length random bytes.
+func GenerateSecureToken(length int) string {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// HashToken returns the hex encoded SHA-256 of token, used to store tokens server-side.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// SignMediaURL returns the media URL with its expiry and a signature, so it can be fetched
+// without authentication until expiresAt.
+func SignMediaURL(url string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return url + "?expires=" + expires + "&signature=" + getMediaURLSignature(url, expires)
+}
+
+// VerifyMediaURL reports whether the signature was made by SignMediaURL for the URL and has
+// not expired.
+func VerifyMediaURL(url, expires, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(getMediaURLSignature(url, expires)))
+}
+
+func getMediaURLSignature(url, expires string) string {
+	mac := hmac.New(sha256.New, GetJWTSecret())
+	mac.Write([]byte("media|" + url + "|" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// EncodeCursor returns an opaque pagination cursor pointing at the item with the given sort keys.
+func EncodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	parts := strings.SplitN(string(decoded), "|", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return time.Time{}, "", errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return createdAt, parts[1], nil
+}
+
 func Contains(s []string, str string) bool {
 	for _, v := range s {
 		if v == str {